@@ -17,9 +17,11 @@ import (
 	"github.com/ghuser/ghproject/pkg/app"
 	"github.com/ghuser/ghproject/pkg/auth"
 	"github.com/ghuser/ghproject/pkg/cache"
+	"github.com/ghuser/ghproject/pkg/cache/cacheadmin"
 	"github.com/ghuser/ghproject/pkg/config"
 	"github.com/ghuser/ghproject/pkg/database"
 	"github.com/ghuser/ghproject/pkg/events"
+	"github.com/ghuser/ghproject/pkg/events/eventsadmin"
 	"github.com/ghuser/ghproject/pkg/httpx"
 	"github.com/ghuser/ghproject/pkg/logger"
 	"github.com/ghuser/ghproject/pkg/telemetry"
@@ -66,7 +68,7 @@ func main() {
 	}
 	defer telemetry.SentryFlush()
 
-	pool, err := database.NewPool(ctx, cfg.DefinitionDatabaseURL, log)
+	pool, err := database.NewPool(ctx, cfg, log)
 	if err != nil {
 		log.Error("failed to connect to database", "error", err)
 		os.Exit(1) //nolint:gocritic // intentional: startup failure, deferred flushes are best-effort
@@ -86,7 +88,11 @@ func main() {
 		os.Exit(1) //nolint:gocritic
 	}
 
-	redisClient, err := cache.NewRedisClient(cfg)
+	if err := eventBus.RegisterLagMetrics(); err != nil {
+		log.Warn("failed to register event lag metrics, continuing without them", "error", err)
+	}
+
+	redisClient, err := cache.NewRedisClient(cfg, log)
 	if err != nil {
 		log.Error("failed to connect to redis", "error", err)
 		os.Exit(1) //nolint:gocritic // intentional: startup failure
@@ -116,6 +122,7 @@ func main() {
 		Redis:    redisClient,
 		//TemporalClient: temporalClient,
 		SessionStore: sessionStore,
+		Cursors:      httpx.NewCursorCodec([]byte(cfg.PaginationCursorKey)),
 	}
 
 	r := httpx.NewRouter(
@@ -138,9 +145,16 @@ func main() {
 	r.Get("/metrics", metricsHandler.ServeHTTP)
 	r.Get("/swagger/*", httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json")))
 	r.Route("/api", func(r chi.Router) {
-		//r.Use(auth.RequireAuth(sessionStore, log))
+		r.Use(auth.RequireAuth(sessionStore, log), database.ReadYourWrites)
 		registerRoutes(r, appConfig)
 	})
+	if cfg.AdminAPIToken != "" {
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(auth.RequireBearerToken(cfg.AdminAPIToken, log))
+			eventsadmin.Routes(r, eventBus, log)
+			cacheadmin.Routes(r, redisClient, log)
+		})
+	}
 
 	srv := httpx.NewServer(":8080", r)
 
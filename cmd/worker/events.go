@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ghuser/ghproject/pkg/app"
+	"github.com/ghuser/ghproject/pkg/events"
+)
+
+const eventsUsage = `usage:
+  worker events groups  [-topic <topic>]
+  worker events reset   -topic <topic> -group <group> (-offset <n> | -time <RFC3339>)
+  worker events replay  -handler <name> [-from <offset>] [-to <offset>]
+  worker events dlq     -topic <topic> [-limit <n>]
+  worker events requeue -topic <topic> -uuid <uuid>
+  worker events purge   -topic <topic>`
+
+// runEventsCommand runs an operator subcommand against the event bus instead
+// of starting the worker:
+//
+//   - groups lists consumer groups with their offset and lag, per topic.
+//   - reset moves one consumer group, with its partition groups, to an offset
+//     or to the last message published before a time. Running workers pick it
+//     up on their next poll.
+//   - replay feeds a topic's messages from offset from through offset to, in
+//     delivery order, to one named handler from eventHandlers, without touching any consumer group's offset. The inbox
+//     and retries are bypassed, so the handler sees every message exactly once.
+//   - dlq lists a topic's dead letters with their failure details; requeue
+//     republishes one to the topic and purge deletes them all.
+func runEventsCommand(ctx context.Context, bus *events.EventBus, a *app.Application, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(eventsUsage)
+	}
+	fs := flag.NewFlagSet("events "+args[0], flag.ContinueOnError)
+	fs.SetOutput(out)
+	topic := fs.String("topic", "", "topic name, e.g. item.created")
+
+	switch args[0] {
+	case "groups":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		groups, err := bus.ConsumerGroups(ctx, *topic)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "TOPIC\tCONSUMER GROUP\tOFFSET\tLAG")
+		for _, g := range groups {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%d\n", g.Topic, g.ConsumerGroup, g.Offset, g.Lag)
+		}
+		return tw.Flush()
+
+	case "reset":
+		group := fs.String("group", "", "consumer group to reset")
+		offset := fs.Int64("offset", -1, "last offset to treat as processed; 0 replays the whole topic")
+		at := fs.String("time", "", "reset to the last message published before this RFC3339 time")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *topic == "" || *group == "" || (*offset < 0) == (*at == "") {
+			return errors.New(eventsUsage)
+		}
+		if *at != "" {
+			t, err := time.Parse(time.RFC3339, *at)
+			if err != nil {
+				return fmt.Errorf("parse -time: %w", err)
+			}
+			if *offset, err = bus.OffsetAt(ctx, *topic, t); err != nil {
+				return err
+			}
+		}
+		if err := bus.ResetOffset(ctx, *topic, *group, *offset); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "reset %s on %s to offset %d\n", *group, *topic, *offset)
+		return nil
+
+	case "replay":
+		name := fs.String("handler", "", "name of the handler to replay into")
+		from := fs.Int64("from", 1, "first offset to replay")
+		to := fs.Int64("to", 0, "last offset to replay; 0 replays through the latest message")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		handlers, err := eventHandlers(a)
+		if err != nil {
+			return err
+		}
+		h, err := findEventHandler(handlers, *name)
+		if err != nil {
+			return err
+		}
+		n, err := bus.Replay(ctx, h.topic, h.name, *from, *to, h.handler)
+		_, _ = fmt.Fprintf(out, "replayed %d messages of %s into %s\n", n, h.topic, h.name)
+		return err
+
+	case "dlq":
+		limit := fs.Int("limit", 100, "maximum number of dead letters to list")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *topic == "" {
+			return errors.New(eventsUsage)
+		}
+		dls, err := bus.ListDeadLetters(ctx, *topic, *limit)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "UUID\tDEAD-LETTERED AT\tHANDLER\tATTEMPTS\tERROR")
+		for _, dl := range dls {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n",
+				dl.UUID, dl.DeadLetteredAt.Format(time.RFC3339), dl.Handler, dl.Attempts, dl.Error)
+		}
+		return tw.Flush()
+
+	case "requeue":
+		uuid := fs.String("uuid", "", "UUID of the dead letter to requeue")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *topic == "" || *uuid == "" {
+			return errors.New(eventsUsage)
+		}
+		if err := bus.RequeueDeadLetter(ctx, *topic, *uuid); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "requeued %s to %s\n", *uuid, *topic)
+		return nil
+
+	case "purge":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *topic == "" {
+			return errors.New(eventsUsage)
+		}
+		n, err := bus.PurgeDeadLetters(ctx, *topic)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(out, "purged %d dead letters of %s\n", n, *topic)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown events command %q\n%s", args[0], eventsUsage)
+	}
+}
+
+// findEventHandler returns the handler registered under name.
+func findEventHandler(handlers []eventHandler, name string) (eventHandler, error) {
+	for _, h := range handlers {
+		if h.name == name {
+			return h, nil
+		}
+	}
+	names := make([]string, len(handlers))
+	for i, h := range handlers {
+		names[i] = h.name
+	}
+	return eventHandler{}, fmt.Errorf("unknown handler %q (available: %v)", name, names)
+}
@@ -2,14 +2,14 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
 
 	"github.com/ghuser/ghproject/pkg/app"
 	"github.com/ghuser/ghproject/pkg/cache"
@@ -17,8 +17,10 @@ import (
 	"github.com/ghuser/ghproject/pkg/database"
 	"github.com/ghuser/ghproject/pkg/events"
 	"github.com/ghuser/ghproject/pkg/logger"
+	"github.com/ghuser/ghproject/pkg/outbox"
 	"github.com/ghuser/ghproject/pkg/telemetry"
 	itemEvents "github.com/ghuser/ghproject/services/item/domain/events"
+	_ "github.com/ghuser/ghproject/services/item/infrastructure/messaging" // registers item event types
 )
 
 func main() {
@@ -49,7 +51,7 @@ func main() {
 	}
 	defer telemetry.SentryFlush()
 
-	pool, err := database.NewPool(ctx, cfg.DefinitionDatabaseURL, log)
+	pool, err := database.NewPool(ctx, cfg, log)
 	if err != nil {
 		log.Error("failed to connect to database", "error", err)
 		os.Exit(1) //nolint:gocritic
@@ -64,7 +66,7 @@ func main() {
 	}
 	defer eventBus.Close() //nolint:errcheck
 
-	redisClient, err := cache.NewRedisClient(cfg)
+	redisClient, err := cache.NewRedisClient(cfg, log)
 	if err != nil {
 		log.Error("failed to connect to redis", "error", err)
 		os.Exit(1) //nolint:gocritic
@@ -87,13 +89,36 @@ func main() {
 		//TemporalClient: temporalClient,
 	}
 
-	if err := registerSubscribers(ctx, appConfig); err != nil {
+	// `worker events ...` runs an operator command instead of the worker.
+	if len(os.Args) > 1 && os.Args[1] == "events" {
+		if err := runEventsCommand(ctx, eventBus, appConfig, os.Args[2:], os.Stdout); err != nil {
+			log.Error("events command failed", "error", err)
+			os.Exit(1) //nolint:gocritic
+		}
+		return
+	}
+
+	if err := registerSubscribers(ctx, appConfig, cfg.ServiceName); err != nil {
 		log.Error("failed to register subscribers", "error", err)
 		os.Exit(1) //nolint:gocritic
 	}
 
+	relay, err := outbox.NewRelay(pool.DB(), eventBus, log, outbox.RelayConfig{
+		BatchSize:    cfg.OutboxBatchSize,
+		PollInterval: cfg.OutboxPollInterval,
+		MaxBackoff:   cfg.OutboxMaxBackoff,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+		Retention:    cfg.OutboxRetention,
+	})
+	if err != nil {
+		log.Error("failed to setup outbox relay", "error", err)
+		os.Exit(1) //nolint:gocritic
+	}
+
 	outboxCtx, cancelOutbox := context.WithCancel(ctx)
-	go runOutboxRelay(outboxCtx, appConfig)
+	go relay.Run(outboxCtx)
+	go eventBus.RunInboxCleanup(outboxCtx, time.Hour, cfg.EventInboxRetention)
+	go eventBus.RunScheduler(outboxCtx, cfg.EventSchedulerInterval)
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -106,72 +131,166 @@ func main() {
 	log.Info("worker stopped")
 }
 
-// registerSubscribers wires all domain event handlers.
-// Add new topics here as more services publish events.
-func registerSubscribers(ctx context.Context, a *app.Application) error {
-	errCh, err := a.EventBus.Subscribe(ctx, itemEvents.TopicItemCreated, handleItemCreated(a))
+// registerSubscribers wires all domain event handlers returned by eventHandlers.
+// Each handler consumes in its own "<service>-<handler>" consumer group, so
+// handlers of the same topic each receive every event instead of splitting
+// them, as they would in the bus's shared default group. A new group starts
+// from the beginning of its topic, which only costs redundant evictions.
+// Subscriptions use the inbox, so an event redelivered after it was processed
+// successfully is acknowledged without running the handler again, and run
+// every attempt through the default middleware stack (tracing, metrics,
+// logging, panic recovery).
+func registerSubscribers(ctx context.Context, a *app.Application, serviceName string) error {
+	handlers, err := eventHandlers(a)
+	if err != nil {
+		return err
+	}
+	mws, err := events.DefaultMiddleware(a.Logger)
 	if err != nil {
 		return err
 	}
 
-	// Drain subscriber errors in background so the channel never blocks.
-	go func() {
-		for err := range errCh {
-			a.Logger.ErrorContext(ctx, "subscriber error",
-				"topic", itemEvents.TopicItemCreated,
-				"error", err,
-			)
+	topics := make([]string, 0, len(handlers))
+	for _, h := range handlers {
+		errCh, err := a.EventBus.Subscribe(ctx, h.topic, h.handler,
+			events.WithHandlerName(h.name),
+			events.WithConsumerGroup(serviceName+"-"+h.name),
+			events.WithInbox(),
+			events.WithMiddleware(mws...),
+		)
+		if err != nil {
+			return err
 		}
-	}()
+		go func() {
+			for err := range errCh {
+				a.Logger.ErrorContext(ctx, "subscriber error",
+					"topic", h.topic,
+					"handler", h.name,
+					"error", err,
+				)
+			}
+		}()
+		topics = append(topics, h.topic)
+	}
 
-	a.Logger.Info("event subscribers registered", "topics", []string{itemEvents.TopicItemCreated})
+	a.Logger.Info("event subscribers registered", "topics", topics)
 	return nil
 }
 
+// eventHandler is a subscriber with a stable name. The name identifies it in
+// dead-letter metadata, logs and metrics, and selects it for `worker events replay`.
+type eventHandler struct {
+	name    string
+	topic   string
+	handler events.Handler
+}
+
+// eventHandlers returns every domain event handler of the worker.
+// Add new handlers here as more services publish events.
+func eventHandlers(a *app.Application) ([]eventHandler, error) {
+	var handlers []eventHandler
+	for _, add := range []func() (eventHandler, error){
+		func() (eventHandler, error) { return newEventHandler("item.created.cache", handleItemCreated(a)) },
+		func() (eventHandler, error) { return newEventHandler("item.updated.cache", handleItemUpdated(a)) },
+		func() (eventHandler, error) { return newEventHandler("item.deleted.cache", handleItemDeleted(a)) },
+		func() (eventHandler, error) {
+			return newEventHandler("item.created.list-cache", handleItemListChanged(a, func(e itemEvents.ItemCreatedEvent) uuid.UUID {
+				return e.OrgID
+			}))
+		},
+		func() (eventHandler, error) {
+			return newEventHandler("item.updated.list-cache", handleItemListChanged(a, func(e itemEvents.ItemUpdatedEvent) uuid.UUID {
+				return e.OrgID
+			}))
+		},
+		func() (eventHandler, error) {
+			return newEventHandler("item.deleted.list-cache", handleItemListChanged(a, func(e itemEvents.ItemDeletedEvent) uuid.UUID {
+				return e.OrgID
+			}))
+		},
+	} {
+		h, err := add()
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, nil
+}
+
+// newEventHandler names a typed handler and binds it to its event's registered topic.
+func newEventHandler[T any](name string, handler func(context.Context, T) error) (eventHandler, error) {
+	topic, err := events.TopicOf[T]()
+	if err != nil {
+		return eventHandler{}, err
+	}
+	return eventHandler{name: name, topic: topic, handler: events.TypedHandler(handler)}, nil
+}
+
 // handleItemCreated returns a handler for item.created events.
-// Handlers must be idempotent — EventBus retries up to 3× on failure.
-// Warms the Redis read-model cache so subsequent GetByID calls are served from cache.
-func handleItemCreated(a *app.Application) func(context.Context, *message.Message) error {
+// Evicts the item from the Redis read model, dropping any cached absence so
+// the next GetByID loads the new item. item.created, item.updated and
+// item.deleted are separate topics with no ordering between them, so the
+// handlers evict rather than write event snapshots, which a late event could
+// make stale. Evicting is idempotent, so redelivery is safe.
+func handleItemCreated(a *app.Application) func(context.Context, itemEvents.ItemCreatedEvent) error {
 	itemCache := cache.NewItemCache(a.Redis)
-	return func(ctx context.Context, msg *message.Message) error {
-		var evt itemEvents.ItemCreatedEvent
-		if err := json.Unmarshal(msg.Payload, &evt); err != nil {
-			return err
+	return func(ctx context.Context, evt itemEvents.ItemCreatedEvent) error {
+		if err := itemCache.Delete(ctx, evt.OrgID, evt.ItemID); err != nil {
+			return fmt.Errorf("evict cached item %s: %w", evt.ItemID, err)
+		}
+
+		a.Logger.InfoContext(ctx, "cache evicted",
+			"item_id", evt.ItemID, "org_id", evt.OrgID)
+		return nil
+	}
+}
+
+// handleItemUpdated returns a handler for item.updated events.
+// Evicts the item from the Redis read model so renames are visible to cached
+// reads; see handleItemCreated for why it does not write the After snapshot.
+// Failures are returned so the bus retries: a stale read model is worse than
+// a missing one.
+func handleItemUpdated(a *app.Application) func(context.Context, itemEvents.ItemUpdatedEvent) error {
+	itemCache := cache.NewItemCache(a.Redis)
+	return func(ctx context.Context, evt itemEvents.ItemUpdatedEvent) error {
+		if err := itemCache.Delete(ctx, evt.OrgID, evt.ItemID); err != nil {
+			return fmt.Errorf("evict cached item %s: %w", evt.ItemID, err)
 		}
 
-		if err := itemCache.Set(ctx, &cache.CachedItem{
-			ID:        evt.ItemID,
-			OrgID:     evt.OrgID,
-			Name:      evt.Name,
-			CreatedAt: evt.OccurredAt,
-		}); err != nil {
-			// Cache warming is best-effort; log but do not fail the handler.
-			a.Logger.WarnContext(ctx, "cache warm failed for item.created",
-				"item_id", evt.ItemID, "error", err)
-		} else {
-			a.Logger.InfoContext(ctx, "cache warmed",
-				"item_id", evt.ItemID, "org_id", evt.OrgID)
+		a.Logger.InfoContext(ctx, "cache evicted",
+			"item_id", evt.ItemID, "org_id", evt.OrgID)
+		return nil
+	}
+}
+
+// handleItemDeleted returns a handler for item.deleted events.
+// Evicts the item from the Redis read model; deleting a missing key is a no-op,
+// so redelivery is safe.
+func handleItemDeleted(a *app.Application) func(context.Context, itemEvents.ItemDeletedEvent) error {
+	itemCache := cache.NewItemCache(a.Redis)
+	return func(ctx context.Context, evt itemEvents.ItemDeletedEvent) error {
+		if err := itemCache.Delete(ctx, evt.OrgID, evt.ItemID); err != nil {
+			return fmt.Errorf("evict cached item %s: %w", evt.ItemID, err)
 		}
 
+		a.Logger.InfoContext(ctx, "cache evicted",
+			"item_id", evt.ItemID, "org_id", evt.OrgID)
 		return nil
 	}
 }
 
-// runOutboxRelay polls the outbox for unpublished events and forwards them to
-// the EventBus. Runs until ctx is cancelled.
-// The Watermill Forwarder (started in cmd/api/main.go) handles at-least-once
-// delivery; this relay is a secondary safety net for future outbox tables.
-func runOutboxRelay(ctx context.Context, a *app.Application) {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			a.Logger.Info("outbox relay shutting down")
-			return
-		case <-ticker.C:
-			// TODO: query outbox table, publish unpublished events, mark as published
+// handleItemListChanged returns a handler for item events that flushes the
+// cached list pages of the event's org, so the next List reloads them.
+// Failures are returned so the bus retries; flushing twice only costs a reload.
+func handleItemListChanged[T any](a *app.Application, orgID func(T) uuid.UUID) func(context.Context, T) error {
+	return func(ctx context.Context, evt T) error {
+		org := orgID(evt)
+		if err := a.Redis.InvalidateTags(ctx, cache.ItemListTag(org)); err != nil {
+			return fmt.Errorf("invalidate cached item lists of org %s: %w", org, err)
 		}
+
+		a.Logger.InfoContext(ctx, "item list cache invalidated", "org_id", org)
+		return nil
 	}
 }
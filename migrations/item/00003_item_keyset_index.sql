@@ -0,0 +1,8 @@
+-- +goose Up
+-- Supports keyset pagination: WHERE org_id = ? AND (created_at, id) < (?, ?)
+-- ORDER BY created_at DESC, id DESC.
+CREATE INDEX IF NOT EXISTS idx_items_org_id_created_at_id
+    ON item.items (org_id, created_at DESC, id DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS item.idx_items_org_id_created_at_id;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE SCHEMA IF NOT EXISTS outbox;
+
+CREATE TABLE outbox.messages (
+    id           BIGSERIAL   PRIMARY KEY,
+    uuid         TEXT        NOT NULL UNIQUE,
+    topic        TEXT        NOT NULL,
+    payload      BYTEA       NOT NULL,
+    metadata     JSONB       NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ,
+    attempts     INT         NOT NULL DEFAULT 0,
+    last_error   TEXT
+);
+
+-- The relay only ever scans unpublished rows in insertion order.
+CREATE INDEX messages_unpublished_idx ON outbox.messages (id) WHERE published_at IS NULL;
+CREATE INDEX messages_published_at_idx ON outbox.messages (published_at) WHERE published_at IS NOT NULL;
+
+-- +goose Down
+DROP TABLE IF EXISTS outbox.messages;
+DROP SCHEMA IF EXISTS outbox;
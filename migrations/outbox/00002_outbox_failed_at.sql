@@ -0,0 +1,15 @@
+-- +goose Up
+-- Rows that exhaust the relay's publish attempts are marked failed and skipped,
+-- so they no longer block the rows behind them. Clear failed_at (and attempts)
+-- to have the relay retry a row.
+ALTER TABLE outbox.messages ADD COLUMN failed_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox.messages_unpublished_idx;
+CREATE INDEX messages_unpublished_idx ON outbox.messages (id)
+    WHERE published_at IS NULL AND failed_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS outbox.messages_unpublished_idx;
+CREATE INDEX messages_unpublished_idx ON outbox.messages (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox.messages DROP COLUMN IF EXISTS failed_at;
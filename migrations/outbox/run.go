@@ -0,0 +1,25 @@
+package main
+
+import (
+	"embed"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/migrator"
+)
+
+//go:embed *.sql
+var MigrationsFS embed.FS
+
+// The outbox is shared by all services, so it keeps its own goose version table
+// instead of interleaving version numbers with a service's migrations.
+const versionTable = "outbox_goose_db_version"
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		panic(err)
+	}
+	if err := migrator.RunMigrationsWithTable(cfg.DefinitionDatabaseURL, versionTable, MigrationsFS); err != nil {
+		panic(err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/ghuser/ghproject/pkg/cache"
 	"github.com/ghuser/ghproject/pkg/database"
 	"github.com/ghuser/ghproject/pkg/events"
+	"github.com/ghuser/ghproject/pkg/httpx"
 	"github.com/ghuser/ghproject/pkg/logger"
 	"github.com/ghuser/ghproject/pkg/workflows"
 	"github.com/gorilla/sessions"
@@ -22,8 +23,9 @@ import (
 type Application struct {
 	Db             *database.Database
 	Logger         logger.Logger
-	EventBus       *events.EventBus
+	EventBus       events.Bus
 	Redis          *cache.RedisClient
 	TemporalClient *workflows.TemporalClient
-	SessionStore   sessions.Store // Redis-backed session store; nil in worker process
+	SessionStore   sessions.Store     // Redis-backed session store; nil in worker process
+	Cursors        *httpx.CursorCodec // Signs list pagination cursors; nil in worker process
 }
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/ghuser/ghproject/pkg/httpx"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// RequireBearerToken is a chi middleware for operator endpoints that are not
+// tied to a user session. It requires an "Authorization: Bearer <token>" header
+// matching token, compared in constant time. Returns 401 Unauthorized otherwise.
+// An empty token rejects every request.
+func RequireBearerToken(token string, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				log.WarnContext(r.Context(), "invalid bearer token", "path", r.URL.Path)
+				httpx.JSON(w, http.StatusUnauthorized, map[string]string{"error": "authentication required"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
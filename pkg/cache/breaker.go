@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// ErrCircuitOpen is returned instead of calling Redis while the circuit
+// breaker of a RedisClient is open.
+var ErrCircuitOpen = errors.New("cache: circuit open")
+
+// breaker stops caches from calling Redis while it is consistently failing,
+// so requests fall back to their source without waiting on Redis timeouts.
+//
+// After threshold consecutive failures it opens for cooldown, rejecting calls
+// with ErrCircuitOpen. The first call after the cooldown is let through as a
+// probe: success closes the breaker, failure reopens it for another cooldown.
+// A nil breaker never opens.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	log       logger.Logger
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int       // consecutive, while closed
+	openUntil time.Time // zero while closed
+	probing   bool      // a probe is in flight
+}
+
+func newBreaker(threshold int, cooldown time.Duration, log logger.Logger) *breaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &breaker{threshold: threshold, cooldown: cooldown, log: log, now: time.Now}
+}
+
+// allow returns ErrCircuitOpen if a call must not be made. Every allowed call
+// must be followed by record.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return ErrCircuitOpen
+	}
+	b.probing = true
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed call.
+func (b *breaker) record(ctx context.Context, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := b.probing
+	b.probing = false
+
+	if !isFailure(err) {
+		if probe {
+			b.log.InfoContext(ctx, "cache: circuit closed, redis recovered")
+		}
+		b.failures, b.openUntil = 0, time.Time{}
+		return
+	}
+	if !probe {
+		b.failures++
+		if !b.openUntil.IsZero() || b.failures < b.threshold {
+			return
+		}
+	}
+	b.openUntil = b.now().Add(b.cooldown)
+	b.log.WarnContext(ctx, "cache: circuit opened, skipping redis",
+		"failures", b.failures, "cooldown", b.cooldown, "error", err)
+}
+
+// isFailure reports whether err means Redis is unhealthy. Misses, error
+// replies from a responsive server and the caller giving up are not failures.
+func isFailure(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var reply redis.Error
+	return !errors.As(err, &reply)
+}
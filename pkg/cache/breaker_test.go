@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestBreaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(0, 0)
+	b := newBreaker(3, time.Minute, testLogger)
+	b.now = func() time.Time { return now }
+
+	down := errors.New("dial tcp: connection refused")
+	call := func(err error) error {
+		if err := b.allow(); err != nil {
+			return err
+		}
+		b.record(ctx, err)
+		return nil
+	}
+
+	// Misses and error replies do not count; a success resets the count.
+	for _, err := range []error{down, down, redis.Nil, context.Canceled, nil, down, down} {
+		if got := call(err); got != nil {
+			t.Fatalf("call before threshold: got %v", got)
+		}
+	}
+	if err := call(down); err != nil {
+		t.Fatalf("third consecutive failure: got %v", err)
+	}
+	if err := call(nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after threshold: got %v, want ErrCircuitOpen", err)
+	}
+
+	// After the cooldown one probe goes through; its failure reopens.
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("probe after cooldown: got %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("second call while probing: got %v, want ErrCircuitOpen", err)
+	}
+	b.record(ctx, down)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("after failed probe: got %v, want ErrCircuitOpen", err)
+	}
+
+	// A successful probe closes it.
+	now = now.Add(time.Minute)
+	if err := call(nil); err != nil {
+		t.Fatalf("probe: got %v", err)
+	}
+	for i := range 2 {
+		if err := call(down); err != nil {
+			t.Fatalf("failure %d after recovery: got %v, want closed", i+1, err)
+		}
+	}
+
+	disabled := newBreaker(0, time.Minute, testLogger)
+	for range 10 {
+		if err := disabled.allow(); err != nil {
+			t.Fatalf("disabled breaker: got %v", err)
+		}
+		disabled.record(ctx, down)
+	}
+}
+
+func TestTyped_CircuitBreaker(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := newCacheMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	if err != nil {
+		t.Fatalf("newCacheMetrics: %v", err)
+	}
+	c, mr := newTestTyped(t, TypedOptions[int, testValue]{})
+	c.metrics = m
+	c.redis.breaker = newBreaker(2, time.Hour, testLogger)
+	ctx := context.Background()
+
+	if err := c.Set(ctx, 1, testValue{Name: "cached"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if _, err := c.Get(ctx, 1); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	mr.Close()
+
+	load := func(context.Context) (testValue, error) { return testValue{Name: "db"}, nil }
+	for i := range 4 {
+		v, err := c.GetOrLoad(ctx, 1, load)
+		if err != nil || v.Name != "db" {
+			t.Fatalf("GetOrLoad %d with Redis down: got %+v, %v; want the loaded value", i, v, err)
+		}
+	}
+	if _, err := c.Get(ctx, 1); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get with circuit open: got %v, want ErrCircuitOpen", err)
+	}
+
+	// The first GetOrLoad fails both its lookup and its store, opening the
+	// circuit; the remaining lookups skip Redis.
+	want := map[string]int64{"redis hit": 1, "redis error": 1, "redis skipped": 4}
+	if got := lookupCounts(t, reader); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("lookups: got %v, want %v", got, want)
+	}
+	want = map[string]int64{"set success": 1, "get success": 1, "get failure": 1, "set failure": 1}
+	if got := callCounts(t, reader); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("timed calls: got %v, want %v", got, want)
+	}
+}
+
+// callCounts collects the cache.duration sample counts from reader, keyed
+// "{operation} {outcome}".
+func callCounts(t *testing.T, reader sdkmetric.Reader) map[string]uint64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	counts := map[string]uint64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			hist, ok := m.Data.(metricdata.Histogram[float64])
+			if !ok || m.Name != "cache.duration" {
+				continue
+			}
+			for _, dp := range hist.DataPoints {
+				op, _ := dp.Attributes.Value("operation")
+				outcome, _ := dp.Attributes.Value("outcome")
+				counts[op.AsString()+" "+outcome.AsString()] += dp.Count
+			}
+		}
+	}
+	return counts
+}
@@ -0,0 +1,72 @@
+// Package cacheadmin exposes cache invalidation over HTTP for operators:
+// flushing every cached entry of an organization (e.g. after suspending it or
+// bulk-importing its data) or of any other cache tag.
+package cacheadmin
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ghuser/ghproject/pkg/cache"
+	"github.com/ghuser/ghproject/pkg/httpx"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// Invalidator is the subset of *cache.RedisClient the admin endpoints use.
+type Invalidator interface {
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
+
+// InvalidateResponse lists the tags whose entries were invalidated.
+type InvalidateResponse struct {
+	Tags []string `json:"tags"`
+}
+
+// Routes registers the cache admin endpoints on r:
+//
+//	POST /cache/orgs/{orgID}/invalidate
+//	POST /cache/tags/{tag}/invalidate
+//
+// Tags are cache.OrgTag and cache.TypeTag values, such as "org:{orgID}" or
+// "type:item". Mount them behind operator authentication; flushing a busy
+// cache shifts its read load onto the database.
+func Routes(r chi.Router, inv Invalidator, log logger.Logger) {
+	r.Post("/cache/orgs/{orgID}/invalidate", invalidateOrg(inv, log))
+	r.Post("/cache/tags/{tag}/invalidate", invalidateTag(inv, log))
+}
+
+func invalidateOrg(inv Invalidator, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID, err := uuid.Parse(chi.URLParam(r, "orgID"))
+		if err != nil {
+			httpx.JSONError(w, http.StatusBadRequest, "invalid org id")
+			return
+		}
+		invalidate(w, r, inv, log, cache.OrgTag(orgID))
+	}
+}
+
+func invalidateTag(inv Invalidator, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tag := chi.URLParam(r, "tag")
+		if strings.TrimSpace(tag) == "" {
+			httpx.JSONError(w, http.StatusBadRequest, "tag is required")
+			return
+		}
+		invalidate(w, r, inv, log, tag)
+	}
+}
+
+func invalidate(w http.ResponseWriter, r *http.Request, inv Invalidator, log logger.Logger, tag string) {
+	if err := inv.InvalidateTags(r.Context(), tag); err != nil {
+		log.ErrorContext(r.Context(), "cache admin request failed", "tag", tag, "error", err)
+		httpx.JSONError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	log.InfoContext(r.Context(), "cache tag invalidated", "tag", tag)
+	httpx.JSON(w, http.StatusOK, InvalidateResponse{Tags: []string{tag}})
+}
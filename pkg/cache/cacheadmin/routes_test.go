@@ -0,0 +1,74 @@
+package cacheadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// fakeInvalidator records invalidated tags.
+type fakeInvalidator struct {
+	tags []string
+	err  error
+}
+
+func (f *fakeInvalidator) InvalidateTags(_ context.Context, tags ...string) error {
+	f.tags = append(f.tags, tags...)
+	return f.err
+}
+
+func TestInvalidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		err        error
+		wantStatus int
+		wantTags   []string
+	}{
+		{
+			name:       "org",
+			path:       "/cache/orgs/0b7e4a52-3c57-4a8e-9d3e-5d2f8f0a1c11/invalidate",
+			wantStatus: http.StatusOK,
+			wantTags:   []string{"org:0b7e4a52-3c57-4a8e-9d3e-5d2f8f0a1c11"},
+		},
+		{"invalid org", "/cache/orgs/nope/invalidate", nil, http.StatusBadRequest, nil},
+		{"tag", "/cache/tags/type:item/invalidate", nil, http.StatusOK, []string{"type:item"}},
+		{"failure", "/cache/tags/type:item/invalidate", errors.New("redis down"), http.StatusInternalServerError, []string{"type:item"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeInvalidator{err: tt.err}
+			r := chi.NewRouter()
+			Routes(r, f, logger.New(&config.Config{LogLevel: "error"}))
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if !slices.Equal(f.tags, tt.wantTags) {
+				t.Errorf("invalidated tags = %v, want %v", f.tags, tt.wantTags)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var body InvalidateResponse
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !slices.Equal(body.Tags, tt.wantTags) {
+				t.Errorf("response tags = %v, want %v", body.Tags, tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// invalidationChannel is the Redis pub/sub channel on which caches announce
+// the keys they changed, so other instances drop them from their local tier.
+const invalidationChannel = "cache:invalidate"
+
+// tagMarker prefixes the tags of an invalidation message, which evict every
+// entry carrying them, to tell them from keys.
+const tagMarker = "#"
+
+// invalidationRetryDelay is the pause before resubscribing after the
+// subscription connection fails.
+const invalidationRetryDelay = time.Second
+
+// invalidator keeps the local tiers of all caches on one RedisClient
+// consistent across instances. Each change is published as
+// "{origin} {key} [{key}...]"; instances evict the keys from their local
+// tiers, ignoring their own messages. Tags are published as "#{tag}" and
+// evict the local entries carrying them.
+//
+// Messages published while an instance is disconnected are lost, so every
+// (re)subscription purges the local tiers; LocalTTL bounds staleness beyond
+// that.
+type invalidator struct {
+	client *redis.Client
+	origin string
+
+	mu      sync.Mutex
+	locals  map[string]*lru // by key prefix, including the trailing ":"
+	pubsub  *redis.PubSub   // nil until the first local tier registers
+	closing chan struct{}
+	done    chan struct{}
+}
+
+func newInvalidator(client *redis.Client) *invalidator {
+	return &invalidator{
+		client:  client,
+		origin:  uuid.NewString(),
+		locals:  make(map[string]*lru),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// register routes invalidations of keys under prefix to local, subscribing
+// on first use.
+func (i *invalidator) register(prefix string, local *lru) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.locals[prefix+":"] = local
+	if i.pubsub == nil {
+		i.pubsub = i.client.Subscribe(context.Background(), invalidationChannel)
+		go i.run(i.pubsub)
+	}
+}
+
+// publish announces that keys changed. Failures are ignored: other instances
+// then serve their local copies until LocalTTL expires them.
+func (i *invalidator) publish(ctx context.Context, keys ...string) {
+	payload := i.origin + " " + strings.Join(keys, " ")
+	_ = i.client.Publish(ctx, invalidationChannel, payload).Err()
+}
+
+// publishTags announces that the entries carrying tags changed.
+func (i *invalidator) publishTags(ctx context.Context, tags ...string) {
+	fields := make([]string, len(tags))
+	for n, tag := range tags {
+		fields[n] = tagMarker + tag
+	}
+	i.publish(ctx, fields...)
+}
+
+// run receives invalidations until close.
+func (i *invalidator) run(ps *redis.PubSub) {
+	defer close(i.done)
+	for {
+		msg, err := ps.Receive(context.Background())
+		if err != nil {
+			select {
+			case <-i.closing:
+				return
+			case <-time.After(invalidationRetryDelay):
+				continue // Receive reconnects and resubscribes
+			}
+		}
+		switch m := msg.(type) {
+		case *redis.Subscription:
+			i.purge()
+		case *redis.Message:
+			i.handle(m.Payload)
+		}
+	}
+}
+
+// handle evicts the keys and tagged entries of one invalidation message
+// published by another instance.
+func (i *invalidator) handle(payload string) {
+	fields := strings.Fields(payload)
+	if len(fields) < 2 || fields[0] == i.origin {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, field := range fields[1:] {
+		if tag, ok := strings.CutPrefix(field, tagMarker); ok {
+			for _, local := range i.locals {
+				local.removeTagged(tag)
+			}
+			continue
+		}
+		for prefix, local := range i.locals {
+			if strings.HasPrefix(field, prefix) {
+				local.remove(field)
+			}
+		}
+	}
+}
+
+// evictTags drops the entries carrying tags from this instance's local tiers.
+func (i *invalidator) evictTags(tags ...string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, local := range i.locals {
+		local.removeTagged(tags...)
+	}
+}
+
+// purge empties every local tier.
+func (i *invalidator) purge() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, local := range i.locals {
+		local.purge()
+	}
+}
+
+// close stops the subscription, if any.
+func (i *invalidator) close() error {
+	i.mu.Lock()
+	ps := i.pubsub
+	i.mu.Unlock()
+	if ps == nil {
+		return nil
+	}
+	close(i.closing)
+	err := ps.Close()
+	<-i.done
+	return err
+}
@@ -2,23 +2,26 @@ package cache
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
 )
 
 const (
 	// ItemCacheTTL is the time-to-live for cached items.
 	ItemCacheTTL = 24 * time.Hour
 
+	// itemCacheNegativeTTL is how long a missing item is remembered.
+	itemCacheNegativeTTL = 30 * time.Second
+	// itemCacheJitter spreads item expiries by ±10%.
+	itemCacheJitter = 0.1
+
 	itemCacheKeyPrefix = "item"
 )
 
 // CachedItem is the denormalized read model stored in Redis.
-// Fields are stored as a Redis hash. Additional fields from other aggregates
-// can be added here for read optimization without touching the domain model.
+// Additional fields from other aggregates can be added here for read
+// optimization without touching the domain model.
 type CachedItem struct {
 	ID        uuid.UUID `json:"id"`
 	OrgID     uuid.UUID `json:"org_id"`
@@ -26,78 +29,76 @@ type CachedItem struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ItemKey addresses a cached item.
+type ItemKey struct {
+	OrgID  uuid.UUID
+	ItemID uuid.UUID
+}
+
 // ItemCache provides structured read/write operations for item cache entries.
 // Keys are scoped by orgID to prevent cross-tenant data leakage.
 // Key format: "item:{orgID}:{itemID}"
+// Entries are tagged with OrgTag and TypeTag("item") for InvalidateTags.
 type ItemCache struct {
-	client *RedisClient
+	typed *Typed[ItemKey, CachedItem]
 }
 
-// NewItemCache creates a new ItemCache backed by the given RedisClient.
+// NewItemCache creates a new ItemCache backed by the given RedisClient, with
+// the client's in-process tier settings.
 func NewItemCache(r *RedisClient) *ItemCache {
-	return &ItemCache{client: r}
+	return &ItemCache{typed: NewTyped(r, TypedOptions[ItemKey, CachedItem]{
+		Prefix: itemCacheKeyPrefix,
+		Key: func(k ItemKey) string {
+			return k.OrgID.String() + ":" + k.ItemID.String()
+		},
+		Tags: func(k ItemKey) []string {
+			return []string{OrgTag(k.OrgID)}
+		},
+		TTL:         ItemCacheTTL,
+		Jitter:      itemCacheJitter,
+		NegativeTTL: itemCacheNegativeTTL,
+		LocalSize:   r.localSize,
+		LocalTTL:    r.localTTL,
+	})}
 }
 
 // Get retrieves a cached item by org + item ID.
-// Returns redis.Nil error when the key does not exist or has expired.
+// Returns ErrMiss when the item is not cached and ErrNotFound when it is
+// cached as missing.
 func (c *ItemCache) Get(ctx context.Context, orgID, itemID uuid.UUID) (*CachedItem, error) {
-	key := c.key(orgID, itemID)
-	vals, err := c.client.Client().HGetAll(ctx, key).Result()
+	item, err := c.typed.Get(ctx, ItemKey{OrgID: orgID, ItemID: itemID})
 	if err != nil {
-		return nil, fmt.Errorf("cache get: %w", err)
-	}
-	if len(vals) == 0 {
-		return nil, redis.Nil // key not found
+		return nil, err
 	}
+	return &item, nil
+}
 
-	id, err := uuid.Parse(vals["id"])
-	if err != nil {
-		return nil, fmt.Errorf("cache parse id: %w", err)
-	}
-	oid, err := uuid.Parse(vals["org_id"])
+// GetOrLoad returns the cached item, or loads and caches it, sharing one load
+// among concurrent callers. load returns ErrNotFound for a missing item.
+func (c *ItemCache) GetOrLoad(
+	ctx context.Context,
+	orgID, itemID uuid.UUID,
+	load func(ctx context.Context) (*CachedItem, error),
+) (*CachedItem, error) {
+	item, err := c.typed.GetOrLoad(ctx, ItemKey{OrgID: orgID, ItemID: itemID}, func(ctx context.Context) (CachedItem, error) {
+		item, err := load(ctx)
+		if err != nil {
+			return CachedItem{}, err
+		}
+		return *item, nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("cache parse org_id: %w", err)
+		return nil, err
 	}
-	createdAt, err := time.Parse(time.RFC3339Nano, vals["created_at"])
-	if err != nil {
-		return nil, fmt.Errorf("cache parse created_at: %w", err)
-	}
-
-	return &CachedItem{
-		ID:        id,
-		OrgID:     oid,
-		Name:      vals["name"],
-		CreatedAt: createdAt,
-	}, nil
+	return &item, nil
 }
 
-// Set writes a cached item as a Redis hash with a 24-hour TTL.
-// Uses a pipeline to set all fields and the TTL atomically.
+// Set writes a cached item with ItemCacheTTL, give or take the jitter.
 func (c *ItemCache) Set(ctx context.Context, item *CachedItem) error {
-	key := c.key(item.OrgID, item.ID)
-	pipe := c.client.Client().Pipeline()
-	pipe.HSet(ctx, key,
-		"id", item.ID.String(),
-		"org_id", item.OrgID.String(),
-		"name", item.Name,
-		"created_at", item.CreatedAt.UTC().Format(time.RFC3339Nano),
-	)
-	pipe.Expire(ctx, key, ItemCacheTTL)
-	if _, err := pipe.Exec(ctx); err != nil {
-		return fmt.Errorf("cache set: %w", err)
-	}
-	return nil
+	return c.typed.Set(ctx, ItemKey{OrgID: item.OrgID, ItemID: item.ID}, *item)
 }
 
 // Delete removes a cached item.
 func (c *ItemCache) Delete(ctx context.Context, orgID, itemID uuid.UUID) error {
-	if err := c.client.Client().Del(ctx, c.key(orgID, itemID)).Err(); err != nil {
-		return fmt.Errorf("cache delete: %w", err)
-	}
-	return nil
-}
-
-// key builds the Redis key: "item:{orgID}:{itemID}"
-func (c *ItemCache) key(orgID, itemID uuid.UUID) string {
-	return fmt.Sprintf("%s:%s:%s", itemCacheKeyPrefix, orgID, itemID)
+	return c.typed.Delete(ctx, ItemKey{OrgID: orgID, ItemID: itemID})
 }
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ItemListCacheTTL is the time-to-live for cached item list pages. Lists
+	// are invalidated by item events, so the TTL only bounds how long a page
+	// survives a lost invalidation.
+	ItemListCacheTTL = 5 * time.Minute
+
+	// itemListCacheJitter spreads list page expiries by ±10%.
+	itemListCacheJitter = 0.1
+
+	itemListCacheKeyPrefix = "item-list"
+)
+
+// ItemListTag returns the tag carried by every cached item list page of
+// orgID. Invalidating it flushes the org's pages without touching its cached
+// items.
+func ItemListTag(orgID uuid.UUID) string {
+	return "item-list:" + orgID.String()
+}
+
+// ItemListKey addresses a cached item list page: the org and the shape of the
+// query. A zero AfterID selects offset pagination; otherwise Offset is
+// ignored, as it is by the query.
+type ItemListKey struct {
+	OrgID          uuid.UUID
+	Limit          int
+	Offset         int
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+	WithTotal      bool
+}
+
+// CachedItemPage is the read model of one page of an org's items.
+type CachedItemPage struct {
+	Items      []CachedItem  `json:"items"`
+	Total      *int          `json:"total,omitempty"`
+	NextCursor *CachedCursor `json:"next_cursor,omitempty"`
+}
+
+// CachedCursor is the keyset position of a cached page's last item.
+type CachedCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// ItemListCache provides read-through caching of item list pages.
+// Key format: "item-list:{orgID}:{limit}:{offset}:{after}:{total}", where
+// after is "{createdAtUnixNano}.{id}" in keyset mode and "-" in offset mode.
+// Entries are tagged with OrgTag, ItemListTag and TypeTag("item-list"); the
+// worker invalidates ItemListTag on every item event.
+type ItemListCache struct {
+	typed *Typed[ItemListKey, CachedItemPage]
+}
+
+// NewItemListCache creates a new ItemListCache backed by the given
+// RedisClient, with the client's in-process tier settings.
+func NewItemListCache(r *RedisClient) *ItemListCache {
+	return &ItemListCache{typed: NewTyped(r, TypedOptions[ItemListKey, CachedItemPage]{
+		Prefix: itemListCacheKeyPrefix,
+		Key:    itemListKey,
+		Tags: func(k ItemListKey) []string {
+			return []string{OrgTag(k.OrgID), ItemListTag(k.OrgID)}
+		},
+		TTL:       ItemListCacheTTL,
+		Jitter:    itemListCacheJitter,
+		LocalSize: r.localSize,
+		LocalTTL:  r.localTTL,
+	})}
+}
+
+// itemListKey renders the query shape of k, normalizing the fields the query
+// ignores so equivalent queries share an entry.
+func itemListKey(k ItemListKey) string {
+	after := "-"
+	if k.AfterID != uuid.Nil {
+		after = fmt.Sprintf("%d.%s", k.AfterCreatedAt.UnixNano(), k.AfterID)
+		k.Offset = 0
+	}
+	return fmt.Sprintf("%s:%d:%d:%s:%t", k.OrgID, k.Limit, k.Offset, after, k.WithTotal)
+}
+
+// GetOrLoad returns the cached page for k, or loads and caches it, sharing one
+// load among concurrent callers.
+func (c *ItemListCache) GetOrLoad(
+	ctx context.Context,
+	k ItemListKey,
+	load func(ctx context.Context) (*CachedItemPage, error),
+) (*CachedItemPage, error) {
+	page, err := c.typed.GetOrLoad(ctx, k, func(ctx context.Context) (CachedItemPage, error) {
+		page, err := load(ctx)
+		if err != nil {
+			return CachedItemPage{}, err
+		}
+		return *page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestItemListCache(t *testing.T) {
+	rc, _ := newTestRedis(t)
+	c := NewItemListCache(rc)
+	ctx := context.Background()
+	orgID, otherOrgID := uuid.New(), uuid.New()
+
+	loads := 0
+	load := func(context.Context) (*CachedItemPage, error) {
+		loads++
+		total := loads
+		return &CachedItemPage{
+			Items: []CachedItem{{ID: uuid.New(), OrgID: orgID, Name: "widget"}},
+			Total: &total,
+		}, nil
+	}
+	list := func(k ItemListKey) *CachedItemPage {
+		t.Helper()
+		page, err := c.GetOrLoad(ctx, k, load)
+		if err != nil {
+			t.Fatalf("GetOrLoad(%+v): %v", k, err)
+		}
+		return page
+	}
+
+	first := ItemListKey{OrgID: orgID, Limit: 20, WithTotal: true}
+	if page := list(first); *page.Total != 1 || len(page.Items) != 1 {
+		t.Fatalf("first load: got %+v", page)
+	}
+	if page := list(first); *page.Total != 1 || loads != 1 {
+		t.Errorf("repeated query: total %d after %d loads, want served from cache", *page.Total, loads)
+	}
+
+	// Every query shape is cached on its own, except an Offset the keyset
+	// query ignores.
+	list(ItemListKey{OrgID: orgID, Limit: 20})
+	list(ItemListKey{OrgID: orgID, Limit: 20, Offset: 20, WithTotal: true})
+	after := time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+	afterID := uuid.New()
+	list(ItemListKey{OrgID: orgID, Limit: 20, AfterCreatedAt: after, AfterID: afterID})
+	list(ItemListKey{OrgID: orgID, Limit: 20, Offset: 40, AfterCreatedAt: after, AfterID: afterID})
+	other := ItemListKey{OrgID: otherOrgID, Limit: 20, WithTotal: true}
+	list(other)
+	if loads != 5 {
+		t.Fatalf("after distinct queries: %d loads, want 5", loads)
+	}
+
+	// Invalidating an org's lists reloads its pages only.
+	if err := rc.InvalidateTags(ctx, ItemListTag(orgID)); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if page := list(first); *page.Total != 6 {
+		t.Errorf("after invalidation: total %d, want reloaded", *page.Total)
+	}
+	if page := list(other); *page.Total != 5 || loads != 6 {
+		t.Errorf("other org after invalidation: total %d after %d loads, want still cached", *page.Total, loads)
+	}
+}
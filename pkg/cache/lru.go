@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is a bounded in-process map of cache entries that evicts the least
+// recently used entry when full and drops entries older than ttl on access.
+// Entries are indexed by their tags, so InvalidateTags can evict them without
+// scanning. It is safe for concurrent use.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+	tagged  map[string]map[*list.Element]struct{} // entries by tag
+	now     func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	data    []byte
+	tags    []string
+	expires time.Time
+}
+
+func newLRU(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+		tagged:  make(map[string]map[*list.Element]struct{}),
+		now:     time.Now,
+	}
+}
+
+// get returns the entry stored under key, if present and not expired.
+func (c *lru) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*lruEntry)
+	if !c.now().Before(e.expires) {
+		c.unlink(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.data, true
+}
+
+// add stores data under key, tagged with tags, and reports whether another
+// entry was evicted to make room.
+func (c *lru) add(key string, data []byte, tags ...string) (evicted bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*lruEntry)
+		c.untag(el)
+		e.data, e.tags, e.expires = data, tags, expires
+		c.tag(el)
+		c.order.MoveToFront(el)
+		return false
+	}
+
+	el := c.order.PushFront(&lruEntry{key: key, data: data, tags: tags, expires: expires})
+	c.entries[key] = el
+	c.tag(el)
+	if c.order.Len() <= c.size {
+		return false
+	}
+	c.unlink(c.order.Back())
+	return true
+}
+
+// remove drops keys.
+func (c *lru) remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := c.entries[key]; ok {
+			c.unlink(el)
+		}
+	}
+}
+
+// removeTagged drops every entry carrying any of tags.
+func (c *lru) removeTagged(tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		for el := range c.tagged[tag] {
+			c.unlink(el)
+		}
+	}
+}
+
+// purge drops every entry.
+func (c *lru) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+	clear(c.tagged)
+}
+
+// unlink drops el from the list and both indexes. c.mu must be held.
+func (c *lru) unlink(el *list.Element) {
+	c.untag(el)
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*lruEntry).key)
+}
+
+// tag indexes el under its tags. c.mu must be held.
+func (c *lru) tag(el *list.Element) {
+	for _, tag := range el.Value.(*lruEntry).tags {
+		if c.tagged[tag] == nil {
+			c.tagged[tag] = make(map[*list.Element]struct{})
+		}
+		c.tagged[tag][el] = struct{}{}
+	}
+}
+
+// untag removes el from the tag index. c.mu must be held.
+func (c *lru) untag(el *list.Element) {
+	for _, tag := range el.Value.(*lruEntry).tags {
+		delete(c.tagged[tag], el)
+		if len(c.tagged[tag]) == 0 {
+			delete(c.tagged, tag)
+		}
+	}
+}
+
+// len returns the number of entries, expired ones included.
+func (c *lru) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
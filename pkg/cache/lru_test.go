@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newLRU(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	has := func(key string) bool {
+		_, ok := c.get(key)
+		return ok
+	}
+
+	c.add("a", []byte("1"))
+	c.add("b", []byte("2"))
+	has("a") // a is now more recently used than b
+	if evicted := c.add("c", []byte("3")); !evicted {
+		t.Error("add to full cache: nothing evicted")
+	}
+	if has("b") || !has("a") || !has("c") {
+		t.Errorf("after eviction: a=%v b=%v c=%v, want b evicted", has("a"), has("b"), has("c"))
+	}
+
+	if evicted := c.add("a", []byte("updated")); evicted {
+		t.Error("update of existing key evicted an entry")
+	}
+	if data, _ := c.get("a"); string(data) != "updated" {
+		t.Errorf("updated value: got %q", data)
+	}
+
+	now = now.Add(time.Minute)
+	if has("a") {
+		t.Error("entry served after its TTL")
+	}
+	if n := c.len(); n != 1 {
+		t.Errorf("expired entry not dropped on access: len %d, want 1", n)
+	}
+
+	c.remove("c")
+	c.add("d", []byte("4"), "t1")
+	c.add("e", []byte("5"), "t1", "t2")
+	c.add("e", []byte("5"), "t2")
+	c.removeTagged("t1")
+	if !has("e") {
+		t.Error("entry evicted by a tag it no longer carries")
+	}
+	c.removeTagged("t2")
+	if has("e") || len(c.tagged) != 0 {
+		t.Errorf("after removeTagged: e=%v, tag index %v", has("e"), c.tagged)
+	}
+
+	c.add("f", []byte("6"), "t1")
+	c.purge()
+	if n := c.len(); n != 0 {
+		t.Errorf("after purge: len %d, want 0", n)
+	}
+}
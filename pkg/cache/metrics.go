@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+const instrumentationName = "github.com/ghuser/ghproject/pkg/cache"
+
+// Cache tiers and lookup results, as recorded in cache.requests.
+const (
+	tierLocal = "local"
+	tierRedis = "redis"
+
+	resultHit     = "hit"
+	resultMiss    = "miss"
+	resultError   = "error"
+	resultSkipped = "skipped" // circuit breaker open
+)
+
+// Redis operations, as recorded in cache.duration and named in spans.
+const (
+	opGet        = "get"
+	opSet        = "set"
+	opDelete     = "delete"
+	opInvalidate = "invalidate_tags"
+)
+
+// cacheMetrics are the instruments all caches record into, told apart by
+// their key prefix.
+type cacheMetrics struct {
+	requests       metric.Int64Counter     // cache.requests{cache, tier, result}
+	localEvictions metric.Int64Counter     // cache.local.evictions{cache}
+	duration       metric.Float64Histogram // cache.duration{cache, operation, outcome}
+}
+
+var (
+	metricsOnce   sync.Once
+	sharedMetrics *cacheMetrics
+)
+
+// metrics returns the cache instruments on the global OTel meter provider,
+// which telemetry.Setup exports on /metrics. If they cannot be created,
+// nothing is recorded.
+func metrics() *cacheMetrics {
+	metricsOnce.Do(func() {
+		m, err := newCacheMetrics(otel.Meter(instrumentationName))
+		if err != nil {
+			m, _ = newCacheMetrics(noop.NewMeterProvider().Meter(instrumentationName))
+		}
+		sharedMetrics = m
+	})
+	return sharedMetrics
+}
+
+func newCacheMetrics(meter metric.Meter) (*cacheMetrics, error) {
+	var (
+		m   cacheMetrics
+		err error
+	)
+	m.requests, err = meter.Int64Counter("cache.requests",
+		metric.WithDescription("Cache lookups, by cache, tier (local, redis) and result (hit, miss, error, skipped)."),
+		metric.WithUnit("{request}"))
+	if err != nil {
+		return nil, err
+	}
+	m.localEvictions, err = meter.Int64Counter("cache.local.evictions",
+		metric.WithDescription("Entries evicted from a full in-process tier to make room."),
+		metric.WithUnit("{entry}"))
+	if err != nil {
+		return nil, err
+	}
+	m.duration, err = meter.Float64Histogram("cache.duration",
+		metric.WithDescription("Duration of Redis calls made by caches, by cache, operation and outcome."),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// recordLookup counts one lookup in tier of the cache named by prefix.
+func (m *cacheMetrics) recordLookup(ctx context.Context, prefix, tier, result string) {
+	m.requests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("cache", prefix),
+		attribute.String("tier", tier),
+		attribute.String("result", result),
+	))
+}
+
+// recordCall records the duration of one Redis call of the cache named by
+// cache. cache is empty for calls not made on behalf of one cache.
+func (m *cacheMetrics) recordCall(ctx context.Context, cache, op string, d time.Duration, err error) {
+	outcome := "success"
+	if isFailure(err) {
+		outcome = "failure"
+	}
+	m.duration.Record(ctx, d.Seconds(), metric.WithAttributes(
+		attribute.String("cache", cache),
+		attribute.String("operation", op),
+		attribute.String("outcome", outcome),
+	))
+}
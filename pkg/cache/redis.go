@@ -2,22 +2,44 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
 )
 
 // RedisClient wraps redis.Client with production-ready configuration.
+//
+// Caches built on the client call Redis through a circuit breaker shared by
+// all of them, and log the errors they recover from to log.
 type RedisClient struct {
-	client *redis.Client
+	client  *redis.Client
+	log     logger.Logger
+	breaker *breaker // nil when disabled
+
+	// In-process tier settings for caches built on this client; see TypedOptions.
+	localSize int
+	localTTL  time.Duration
+
+	invOnce sync.Once
+	inv     *invalidator // started by the first cache with a local tier
 }
 
 // NewRedisClient creates a new Redis client with connection pooling and production-ready settings.
 // It parses the Redis URL from config, applies pool settings, and verifies connectivity via Ping.
-func NewRedisClient(cfg *config.Config) (*RedisClient, error) {
+// Caches built on the client get an in-process tier sized by cfg.CacheLocalSize,
+// and a circuit breaker configured by cfg.CacheBreakerThreshold and
+// cfg.CacheBreakerCooldown.
+func NewRedisClient(cfg *config.Config, log logger.Logger) (*RedisClient, error) {
 	opts, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
@@ -56,7 +78,13 @@ func NewRedisClient(cfg *config.Config) (*RedisClient, error) {
 		return nil, fmt.Errorf("failed to ping redis: %w", err)
 	}
 
-	return &RedisClient{client: rdb}, nil
+	return &RedisClient{
+		client:    rdb,
+		log:       log,
+		breaker:   newBreaker(cfg.CacheBreakerThreshold, cfg.CacheBreakerCooldown, log),
+		localSize: cfg.CacheLocalSize,
+		localTTL:  cfg.CacheLocalTTL,
+	}, nil
 }
 
 // Ping checks the Redis connection health.
@@ -67,12 +95,16 @@ func (r *RedisClient) Ping(ctx context.Context) error {
 	return nil
 }
 
-// Close gracefully shuts down the Redis connection pool.
+// Close stops cache invalidation and gracefully shuts down the Redis connection pool.
 func (r *RedisClient) Close() error {
 	if r.client == nil {
 		return nil
 	}
-	if err := r.client.Close(); err != nil {
+	var invErr error
+	if r.inv != nil {
+		invErr = r.inv.close()
+	}
+	if err := errors.Join(invErr, r.client.Close()); err != nil {
 		return fmt.Errorf("redis close: %w", err)
 	}
 	return nil
@@ -82,3 +114,36 @@ func (r *RedisClient) Close() error {
 func (r *RedisClient) Client() *redis.Client {
 	return r.client
 }
+
+// invalidations returns the client's cross-instance invalidator.
+func (r *RedisClient) invalidations() *invalidator {
+	r.invOnce.Do(func() { r.inv = newInvalidator(r.client) })
+	return r.inv
+}
+
+// call runs fn, one Redis operation op of the cache named by cache, in a
+// client span, recording its duration in m and feeding the circuit breaker.
+// While the breaker is open fn is not run and ErrCircuitOpen is returned.
+func (r *RedisClient) call(ctx context.Context, m *cacheMetrics, cache, op string, fn func(ctx context.Context) error) error {
+	if err := r.breaker.allow(); err != nil {
+		return err
+	}
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, "cache "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "redis"),
+			attribute.String("cache.name", cache),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := fn(ctx)
+	m.recordCall(ctx, cache, op, time.Since(start), err)
+	r.breaker.record(ctx, err)
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
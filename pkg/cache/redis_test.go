@@ -16,14 +16,14 @@ func newTestConfig(url string) *config.Config {
 }
 
 func TestNewRedisClient_InvalidURL(t *testing.T) {
-	_, err := NewRedisClient(newTestConfig("not-a-valid-url"))
+	_, err := NewRedisClient(newTestConfig("not-a-valid-url"), testLogger)
 	if err == nil {
 		t.Fatal("expected error for invalid URL, got nil")
 	}
 }
 
 func TestNewRedisClient_UnreachableHost(t *testing.T) {
-	_, err := NewRedisClient(newTestConfig("redis://localhost:19999"))
+	_, err := NewRedisClient(newTestConfig("redis://localhost:19999"), testLogger)
 	if err == nil {
 		t.Fatal("expected error when Redis is unreachable, got nil")
 	}
@@ -37,7 +37,7 @@ func TestRedisIntegration(t *testing.T) {
 	}
 
 	t.Run("NewRedisClient_Success", func(t *testing.T) {
-		rc, err := NewRedisClient(newTestConfig(redisURL))
+		rc, err := NewRedisClient(newTestConfig(redisURL), testLogger)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -45,7 +45,7 @@ func TestRedisIntegration(t *testing.T) {
 	})
 
 	t.Run("Ping_Success", func(t *testing.T) {
-		rc, err := NewRedisClient(newTestConfig(redisURL))
+		rc, err := NewRedisClient(newTestConfig(redisURL), testLogger)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -57,7 +57,7 @@ func TestRedisIntegration(t *testing.T) {
 	})
 
 	t.Run("Close_Idempotent", func(t *testing.T) {
-		rc, err := NewRedisClient(newTestConfig(redisURL))
+		rc, err := NewRedisClient(newTestConfig(redisURL), testLogger)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -67,7 +67,7 @@ func TestRedisIntegration(t *testing.T) {
 	})
 
 	t.Run("Client_NotNil", func(t *testing.T) {
-		rc, err := NewRedisClient(newTestConfig(redisURL))
+		rc, err := NewRedisClient(newTestConfig(redisURL), testLogger)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// tagKeyPrefix namespaces the generation counters of tags.
+const tagKeyPrefix = "cache:tag:"
+
+// maxTags is how many tags an entry can carry, its own TypeTag included.
+const maxTags = 255
+
+var (
+	// errBadEntry reports a stored entry that cannot be decoded.
+	errBadEntry = errors.New("cache: malformed entry")
+	// errTooManyTags reports an entry with more than maxTags tags, which
+	// cannot be cached: dropping some would leave it stale when they are
+	// invalidated.
+	errTooManyTags = errors.New("cache: too many tags")
+)
+
+// OrgTag returns the tag carried by every cache entry scoped to orgID, for
+// flushing an organization's entries with InvalidateTags.
+func OrgTag(orgID uuid.UUID) string {
+	return "org:" + orgID.String()
+}
+
+// TypeTag returns the tag carried by every entry of the cache with the given
+// key prefix, for flushing the whole cache with InvalidateTags.
+func TypeTag(prefix string) string {
+	return "type:" + prefix
+}
+
+// InvalidateTags drops every cache entry carrying any of tags, on all
+// instances, in O(1) per tag.
+//
+// Each tag has a generation counter, and entries record the generations of
+// their tags when written. Invalidating increments the counters atomically,
+// so entries written before read as misses from then on and expire by their
+// TTL; nothing is scanned or deleted. Local tiers, which cannot check
+// generations, evict the entries carrying tags, here and on the instances the
+// tags are published to.
+func (r *RedisClient) InvalidateTags(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	if err := r.call(ctx, metrics(), "", opInvalidate, func(ctx context.Context) error {
+		_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, tag := range tags {
+				pipe.Incr(ctx, tagKeyPrefix+tag)
+			}
+			return nil
+		})
+		return err
+	}); err != nil {
+		return fmt.Errorf("cache: invalidate tags: %w", err)
+	}
+	inv := r.invalidations()
+	inv.evictTags(tags...)
+	inv.publishTags(ctx, tags...)
+	return nil
+}
+
+// tagKeys returns the generation counter keys of tags.
+func tagKeys(tags []string) []string {
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagKeyPrefix + tag
+	}
+	return keys
+}
+
+// generations fetches the current generation of each tag key; a tag never
+// invalidated is at generation 0.
+func generations(ctx context.Context, client *redis.Client, tagKeys []string) ([]uint64, error) {
+	vals, err := client.MGet(ctx, tagKeys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	return parseGenerations(vals), nil
+}
+
+// parseGenerations converts MGET results of tag keys to generations.
+func parseGenerations(vals []any) []uint64 {
+	gens := make([]uint64, len(vals))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			gens[i], _ = strconv.ParseUint(s, 10, 64)
+		}
+	}
+	return gens
+}
+
+// encodeEntry lays out a stored entry as its kind (kindValue or kindNotFound),
+// the number of tag generations, the generations as big-endian uint64s, and
+// the encoded value.
+func encodeEntry(kind byte, gens []uint64, payload []byte) []byte {
+	data := make([]byte, 0, 2+8*len(gens)+len(payload))
+	data = append(data, kind, byte(len(gens)))
+	for _, g := range gens {
+		data = binary.BigEndian.AppendUint64(data, g)
+	}
+	return append(data, payload...)
+}
+
+// decodeEntry splits a stored entry into the parts written by encodeEntry.
+func decodeEntry(data []byte) (kind byte, gens []uint64, payload []byte, err error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errBadEntry
+	}
+	kind, n, data := data[0], int(data[1]), data[2:]
+	if len(data) < 8*n {
+		return 0, nil, nil, errBadEntry
+	}
+	gens = make([]uint64, n)
+	for i := range gens {
+		gens[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+	return kind, gens, data[8*n:], nil
+}
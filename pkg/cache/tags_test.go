@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestInvalidateTags(t *testing.T) {
+	rc, _ := newTestRedis(t)
+	c := NewTyped(rc, TypedOptions[ItemKey, testValue]{
+		Prefix: "test",
+		Key:    func(k ItemKey) string { return k.OrgID.String() + ":" + k.ItemID.String() },
+		Tags:   func(k ItemKey) []string { return []string{OrgTag(k.OrgID)} },
+		TTL:    time.Hour,
+	})
+	other := NewTyped(rc, TypedOptions[int, testValue]{Prefix: "other", TTL: time.Hour})
+	ctx := context.Background()
+
+	orgA, orgB := uuid.New(), uuid.New()
+	keyA, keyB := ItemKey{OrgID: orgA, ItemID: uuid.New()}, ItemKey{OrgID: orgB, ItemID: uuid.New()}
+	for _, k := range []ItemKey{keyA, keyB} {
+		if err := c.Set(ctx, k, testValue{Name: "v"}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if err := other.Set(ctx, 1, testValue{Name: "v"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	cached := func(k ItemKey) bool {
+		_, err := c.Get(ctx, k)
+		return err == nil
+	}
+
+	if err := rc.InvalidateTags(ctx, OrgTag(orgA)); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if cached(keyA) || !cached(keyB) {
+		t.Errorf("after org invalidation: orgA cached=%v orgB cached=%v, want false, true", cached(keyA), cached(keyB))
+	}
+
+	// Entries written after the invalidation are valid again.
+	if err := c.Set(ctx, keyA, testValue{Name: "v2"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if v, err := c.Get(ctx, keyA); err != nil || v.Name != "v2" {
+		t.Errorf("Get after rewrite: got %+v, %v", v, err)
+	}
+
+	if err := rc.InvalidateTags(ctx, TypeTag("test")); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if cached(keyA) || cached(keyB) {
+		t.Error("type invalidation left entries cached")
+	}
+	if _, err := other.Get(ctx, 1); err != nil {
+		t.Errorf("type invalidation reached another cache: %v", err)
+	}
+}
+
+// TestGetOrLoad_InvalidatedDuringLoad verifies a value loaded before an
+// invalidation is not served after it.
+func TestGetOrLoad_InvalidatedDuringLoad(t *testing.T) {
+	c, _ := newTestTyped(t, TypedOptions[int, testValue]{})
+	rc := &RedisClient{client: c.client, log: testLogger}
+	ctx := context.Background()
+
+	_, err := c.GetOrLoad(ctx, 1, func(ctx context.Context) (testValue, error) {
+		if err := rc.InvalidateTags(ctx, TypeTag("test")); err != nil {
+			t.Errorf("InvalidateTags: %v", err) // load runs on another goroutine
+		}
+		return testValue{Name: "stale"}, nil
+	})
+	if err != nil {
+		t.Fatalf("GetOrLoad: %v", err)
+	}
+	if _, err := c.Get(ctx, 1); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after racing invalidation: got %v, want ErrMiss", err)
+	}
+}
+
+func TestInvalidateTags_LocalTiers(t *testing.T) {
+	// Two instances sharing one Redis, each with a local tier.
+	mr := miniredis.RunT(t)
+	newInstance := func() (*RedisClient, *Typed[int, testValue]) {
+		rc := &RedisClient{client: redis.NewClient(&redis.Options{Addr: mr.Addr()}), log: testLogger}
+		t.Cleanup(func() { _ = rc.Close() })
+		return rc, NewTyped(rc, TypedOptions[int, testValue]{
+			Prefix:    "test",
+			Tags:      func(k int) []string { return []string{fmt.Sprintf("parity:%d", k%2)} },
+			TTL:       time.Hour,
+			LocalSize: 10,
+		})
+	}
+	rcA, a := newInstance()
+	_, b := newInstance()
+	waitUntil(t, "both instances to subscribe", func() bool {
+		return mr.PubSubNumSub(invalidationChannel)[invalidationChannel] == 2
+	})
+	ctx := context.Background()
+
+	for k := range 4 {
+		if err := a.Set(ctx, k, testValue{}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		if _, err := b.Get(ctx, k); err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+	}
+
+	// Only the entries carrying the tag leave the local tiers, on both
+	// instances.
+	if err := rcA.InvalidateTags(ctx, "parity:0"); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	if n := a.local.len(); n != 2 {
+		t.Errorf("local entries on the invalidating instance: got %d, want 2", n)
+	}
+	waitUntil(t, "b to evict the tagged entries", func() bool { return b.local.len() == 2 })
+	for _, key := range []string{"test:1", "test:3"} {
+		if _, ok := b.local.get(key); !ok {
+			t.Errorf("untagged entry %s evicted", key)
+		}
+	}
+	if _, err := b.Get(ctx, 2); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get of an invalidated entry: got %v, want ErrMiss", err)
+	}
+
+	if err := rcA.InvalidateTags(ctx, TypeTag("test")); err != nil {
+		t.Fatalf("InvalidateTags: %v", err)
+	}
+	waitUntil(t, "b to evict the whole cache", func() bool { return b.local.len() == 0 })
+}
+
+func TestTyped_TooManyTags(t *testing.T) {
+	c, mr := newTestTyped(t, TypedOptions[int, testValue]{
+		Tags: func(k int) []string {
+			tags := make([]string, k)
+			for i := range tags {
+				tags[i] = fmt.Sprintf("tag:%d", i)
+			}
+			return tags
+		},
+	})
+	ctx := context.Background()
+
+	if err := c.Set(ctx, maxTags-1, testValue{}); err != nil {
+		t.Fatalf("Set with %d tags: %v", maxTags, err)
+	}
+	if err := c.Set(ctx, maxTags, testValue{}); !errors.Is(err, errTooManyTags) {
+		t.Errorf("Set with %d tags: got %v, want errTooManyTags", maxTags+1, err)
+	}
+	if _, err := c.Get(ctx, maxTags); !errors.Is(err, errTooManyTags) {
+		t.Errorf("Get with %d tags: got %v, want errTooManyTags", maxTags+1, err)
+	}
+
+	// GetOrLoad still serves the loaded value, uncached.
+	load := func(context.Context) (testValue, error) { return testValue{Name: "db"}, nil }
+	if v, err := c.GetOrLoad(ctx, maxTags, load); err != nil || v.Name != "db" {
+		t.Errorf("GetOrLoad: got %+v, %v; want the loaded value", v, err)
+	}
+	if mr.Exists(c.key(maxTags)) {
+		t.Error("entry with too many tags cached")
+	}
+}
+
+func TestEntryEncoding(t *testing.T) {
+	data := encodeEntry(kindValue, []uint64{0, 7}, []byte(`{"name":"x"}`))
+	kind, gens, payload, err := decodeEntry(data)
+	if err != nil {
+		t.Fatalf("decodeEntry: %v", err)
+	}
+	if kind != kindValue || !slices.Equal(gens, []uint64{0, 7}) || string(payload) != `{"name":"x"}` {
+		t.Errorf("decodeEntry = %q, %v, %q", kind, gens, payload)
+	}
+	for _, bad := range [][]byte{nil, {kindValue}, {kindValue, 2, 0, 0}} {
+		if _, _, _, err := decodeEntry(bad); !errors.Is(err, errBadEntry) {
+			t.Errorf("decodeEntry(%v): got %v, want errBadEntry", bad, err)
+		}
+	}
+}
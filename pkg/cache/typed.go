@@ -0,0 +1,395 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+)
+
+// loadTimeout bounds a GetOrLoad loader, which runs detached from the
+// caller's cancellation because concurrent callers share its result.
+const loadTimeout = 10 * time.Second
+
+// Entry kinds, the first byte of every stored value.
+const (
+	kindValue    byte = 'v'
+	kindNotFound byte = 'n'
+)
+
+var (
+	// ErrMiss is returned by Get when the key is not cached.
+	ErrMiss = errors.New("cache: miss")
+	// ErrNotFound marks a value known not to exist. Loaders return it (or wrap
+	// it) to have the absence cached for TypedOptions.NegativeTTL; Get and
+	// GetOrLoad return it on a cached absence.
+	ErrNotFound = errors.New("cache: not found")
+)
+
+// Codec converts cached values to and from bytes.
+type Codec[V any] interface {
+	Marshal(v V) ([]byte, error)
+	Unmarshal(data []byte, v *V) error
+}
+
+// JSONCodec encodes values with encoding/json. It is the default Codec.
+type JSONCodec[V any] struct{}
+
+// Marshal encodes v as JSON.
+func (JSONCodec[V]) Marshal(v V) ([]byte, error) { return json.Marshal(v) }
+
+// Unmarshal decodes JSON data into v.
+func (JSONCodec[V]) Unmarshal(data []byte, v *V) error { return json.Unmarshal(data, v) }
+
+// TypedOptions configures a Typed cache.
+type TypedOptions[K comparable, V any] struct {
+	// Prefix namespaces the keys, e.g. "item" for "item:{key}". Required.
+	Prefix string
+	// Key renders a key's suffix. Defaults to fmt.Sprint.
+	Key func(K) string
+	// Tags returns the tags of k's entry, such as OrgTag, which InvalidateTags
+	// flushes it by. Every entry also carries TypeTag(Prefix), and at most 254
+	// more; keys with more tags are never cached, and Get and Set return an
+	// error for them.
+	Tags func(K) []string
+	// Codec encodes values. Defaults to JSONCodec.
+	Codec Codec[V]
+	// TTL is how long values are cached. Required.
+	TTL time.Duration
+	// Jitter randomizes each TTL by up to ±Jitter of itself (0.1 = ±10%), so
+	// entries written together do not expire together.
+	Jitter float64
+	// NegativeTTL is how long an ErrNotFound from a loader is cached; 0
+	// disables negative caching. Keep it short: a create that does not evict
+	// the key is invisible to GetOrLoad until it expires.
+	NegativeTTL time.Duration
+	// LocalSize bounds an in-process LRU tier in front of Redis; 0 disables
+	// it. Set, SetNotFound and Delete evict the key from every instance's
+	// tier through Redis pub/sub; values GetOrLoad caches after a miss are
+	// not announced. All instances must agree on it: caches without a local
+	// tier publish nothing.
+	LocalSize int
+	// LocalTTL is how long an entry may be served from the local tier, which
+	// bounds staleness when an invalidation is lost. Defaults to
+	// defaultLocalTTL.
+	LocalTTL time.Duration
+}
+
+// defaultLocalTTL is the LocalTTL of caches that do not set one.
+const defaultLocalTTL = 30 * time.Second
+
+// Typed is a Redis-backed cache of V values addressed by K keys, optionally
+// fronted by an in-process LRU tier (see TypedOptions.LocalSize). Lookups are
+// counted per tier in the cache.requests metric; Redis calls are traced, timed
+// in cache.duration and made through the RedisClient's circuit breaker.
+//
+// GetOrLoad implements read-through caching: on a miss it calls the loader
+// once per key across concurrent callers, caches the result and hands it to
+// all of them, so a hot key expiring does not stampede the database.
+type Typed[K comparable, V any] struct {
+	redis   *RedisClient
+	client  *redis.Client
+	opts    TypedOptions[K, V]
+	group   singleflight.Group
+	metrics *cacheMetrics
+
+	local *lru // nil without a local tier
+	inv   *invalidator
+}
+
+// NewTyped returns a Typed cache on client configured by opts.
+func NewTyped[K comparable, V any](client *RedisClient, opts TypedOptions[K, V]) *Typed[K, V] {
+	if opts.Key == nil {
+		opts.Key = func(k K) string { return fmt.Sprint(k) }
+	}
+	if opts.Codec == nil {
+		opts.Codec = JSONCodec[V]{}
+	}
+	c := &Typed[K, V]{
+		redis:   client,
+		client:  client.Client(),
+		opts:    opts,
+		metrics: metrics(),
+		inv:     client.invalidations(),
+	}
+	if opts.LocalSize > 0 {
+		if opts.LocalTTL <= 0 {
+			c.opts.LocalTTL = defaultLocalTTL
+		}
+		c.local = newLRU(opts.LocalSize, c.opts.LocalTTL)
+		c.inv.register(opts.Prefix, c.local)
+	}
+	return c
+}
+
+// Get returns the cached value for k. Returns ErrMiss if k is not cached,
+// ErrNotFound if its absence is, and ErrCircuitOpen if Redis is being skipped.
+func (c *Typed[K, V]) Get(ctx context.Context, k K) (V, error) {
+	v, _, err := c.get(ctx, k)
+	return v, err
+}
+
+// get is Get, also returning the generations of k's tags when it consulted
+// Redis, for caching a value loaded after a miss.
+func (c *Typed[K, V]) get(ctx context.Context, k K) (V, []uint64, error) {
+	var v V
+	key := c.key(k)
+	kind, payload, gens, err := c.lookup(ctx, k)
+	if err != nil {
+		return v, gens, err
+	}
+
+	switch kind {
+	case kindNotFound:
+		return v, gens, ErrNotFound
+	case kindValue:
+		if err := c.opts.Codec.Unmarshal(payload, &v); err != nil {
+			return v, gens, fmt.Errorf("cache: decode %s: %w", key, err)
+		}
+		return v, gens, nil
+	default:
+		return v, gens, fmt.Errorf("cache: get %s: unknown entry kind %q", key, kind)
+	}
+}
+
+// lookup returns the kind and payload of k's entry, from the local tier if it
+// has it and from Redis otherwise, copying Redis hits into the local tier.
+// The entry and its tags' generations are read from Redis in one round trip;
+// an entry written under older generations is a miss.
+func (c *Typed[K, V]) lookup(ctx context.Context, k K) (kind byte, payload []byte, gens []uint64, err error) {
+	key := c.key(k)
+	if c.local != nil {
+		if data, ok := c.local.get(key); ok {
+			c.metrics.recordLookup(ctx, c.opts.Prefix, tierLocal, resultHit)
+			kind, _, payload, err := decodeEntry(data)
+			return kind, payload, nil, err
+		}
+		c.metrics.recordLookup(ctx, c.opts.Prefix, tierLocal, resultMiss)
+	}
+
+	tags, err := c.tags(k)
+	if err != nil {
+		c.metrics.recordLookup(ctx, c.opts.Prefix, tierRedis, resultError)
+		return 0, nil, nil, fmt.Errorf("cache: get %s: %w", key, err)
+	}
+	var vals []any
+	err = c.redis.call(ctx, c.metrics, c.opts.Prefix, opGet, func(ctx context.Context) error {
+		var err error
+		vals, err = c.client.MGet(ctx, append([]string{key}, tagKeys(tags)...)...).Result()
+		return err
+	})
+	if err != nil {
+		result := resultError
+		if errors.Is(err, ErrCircuitOpen) {
+			result = resultSkipped
+		}
+		c.metrics.recordLookup(ctx, c.opts.Prefix, tierRedis, result)
+		return 0, nil, nil, fmt.Errorf("cache: get %s: %w", key, err)
+	}
+	gens = parseGenerations(vals[1:])
+	data, ok := vals[0].(string)
+	if !ok {
+		c.metrics.recordLookup(ctx, c.opts.Prefix, tierRedis, resultMiss)
+		return 0, nil, gens, ErrMiss
+	}
+	kind, written, payload, err := decodeEntry([]byte(data))
+	if err != nil {
+		c.metrics.recordLookup(ctx, c.opts.Prefix, tierRedis, resultError)
+		return 0, nil, gens, fmt.Errorf("cache: get %s: %w", key, err)
+	}
+	if !slices.Equal(written, gens) {
+		c.metrics.recordLookup(ctx, c.opts.Prefix, tierRedis, resultMiss)
+		return 0, nil, gens, ErrMiss
+	}
+	c.metrics.recordLookup(ctx, c.opts.Prefix, tierRedis, resultHit)
+	c.addLocal(ctx, key, []byte(data), tags)
+	return kind, payload, gens, nil
+}
+
+// addLocal stores data, tagged with tags, in the local tier, if there is one.
+func (c *Typed[K, V]) addLocal(ctx context.Context, key string, data []byte, tags []string) {
+	if c.local == nil {
+		return
+	}
+	if c.local.add(key, data, tags...) {
+		c.metrics.localEvictions.Add(ctx, 1, metric.WithAttributes(attribute.String("cache", c.opts.Prefix)))
+	}
+}
+
+// Set caches v under k for the configured TTL, with jitter.
+func (c *Typed[K, V]) Set(ctx context.Context, k K, v V) error {
+	data, err := c.opts.Codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("cache: encode %s: %w", c.key(k), err)
+	}
+	if err := c.put(ctx, k, kindValue, data, c.opts.TTL, nil); err != nil {
+		return err
+	}
+	c.publish(ctx, c.key(k))
+	return nil
+}
+
+// SetNotFound caches the absence of k for the configured NegativeTTL. It is a
+// no-op when negative caching is disabled.
+func (c *Typed[K, V]) SetNotFound(ctx context.Context, k K) error {
+	if c.opts.NegativeTTL <= 0 {
+		return nil
+	}
+	if err := c.put(ctx, k, kindNotFound, nil, c.opts.NegativeTTL, nil); err != nil {
+		return err
+	}
+	c.publish(ctx, c.key(k))
+	return nil
+}
+
+// Delete evicts keys, cached values and cached absences alike, from Redis
+// and from the local tiers of all instances.
+func (c *Typed[K, V]) Delete(ctx context.Context, keys ...K) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = c.key(k)
+	}
+	if c.local != nil {
+		c.local.remove(names...)
+	}
+	if err := c.redis.call(ctx, c.metrics, c.opts.Prefix, opDelete, func(ctx context.Context) error {
+		return c.client.Del(ctx, names...).Err()
+	}); err != nil {
+		return fmt.Errorf("cache: delete: %w", err)
+	}
+	c.publish(ctx, names...)
+	return nil
+}
+
+// GetOrLoad returns the cached value for k, or calls load and caches its
+// result. Concurrent calls for the same k share one load. If load returns
+// ErrNotFound, the absence is cached when negative caching is enabled.
+//
+// The cache is best-effort: if Redis fails or its circuit breaker is open, the
+// value is loaded and returned as if it were a miss, and failures are logged.
+// load runs detached from ctx's cancellation, bounded by loadTimeout, so one
+// caller giving up does not fail the others; each caller still stops waiting
+// when its own ctx is done.
+func (c *Typed[K, V]) GetOrLoad(ctx context.Context, k K, load func(ctx context.Context) (V, error)) (V, error) {
+	v, gens, err := c.get(ctx, k)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		return v, err
+	}
+	if !errors.Is(err, ErrMiss) {
+		c.logFailure(ctx, "cache: lookup failed, loading from source", err)
+	}
+
+	ch := c.group.DoChan(c.key(k), func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		// Cache under the generations seen before loading, so an
+		// invalidation racing the load leaves the entry stale, not wrong.
+		v, err := load(loadCtx)
+		var putErr error
+		switch {
+		case err == nil:
+			data, encErr := c.opts.Codec.Marshal(v)
+			if encErr != nil {
+				putErr = fmt.Errorf("cache: encode %s: %w", c.key(k), encErr)
+			} else {
+				putErr = c.put(loadCtx, k, kindValue, data, c.opts.TTL, gens)
+			}
+		case errors.Is(err, ErrNotFound) && c.opts.NegativeTTL > 0:
+			putErr = c.put(loadCtx, k, kindNotFound, nil, c.opts.NegativeTTL, gens)
+		}
+		if putErr != nil {
+			c.logFailure(loadCtx, "cache: store of loaded value failed", putErr)
+		}
+		return v, err
+	})
+	select {
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	case res := <-ch:
+		v, _ := res.Val.(V)
+		return v, res.Err
+	}
+}
+
+// put stores an entry of kind under k in Redis and the local tier. The entry
+// records gens as its tags' generations, or the current ones if gens is nil.
+// Callers that change k, rather than fill a miss, publish it afterwards.
+func (c *Typed[K, V]) put(ctx context.Context, k K, kind byte, payload []byte, ttl time.Duration, gens []uint64) error {
+	key := c.key(k)
+	tags, err := c.tags(k)
+	if err != nil {
+		return fmt.Errorf("cache: set %s: %w", key, err)
+	}
+	var data []byte
+	if err := c.redis.call(ctx, c.metrics, c.opts.Prefix, opSet, func(ctx context.Context) error {
+		if gens == nil {
+			var err error
+			if gens, err = generations(ctx, c.client, tagKeys(tags)); err != nil {
+				return err
+			}
+		}
+		data = encodeEntry(kind, gens, payload)
+		return c.client.Set(ctx, key, data, c.jitter(ttl)).Err()
+	}); err != nil {
+		return fmt.Errorf("cache: set %s: %w", key, err)
+	}
+	c.addLocal(ctx, key, data, tags)
+	return nil
+}
+
+// publish evicts keys from other instances' local tiers. Without a local tier
+// it does nothing, since the other instances have none either.
+func (c *Typed[K, V]) publish(ctx context.Context, keys ...string) {
+	if c.local != nil {
+		c.inv.publish(ctx, keys...)
+	}
+}
+
+// logFailure logs an error the cache recovered from. Calls skipped by the
+// open circuit breaker are not logged; the breaker logs opening instead.
+func (c *Typed[K, V]) logFailure(ctx context.Context, msg string, err error) {
+	if errors.Is(err, ErrCircuitOpen) {
+		return
+	}
+	c.redis.log.WarnContext(ctx, msg, "cache", c.opts.Prefix, "error", err)
+}
+
+// jitter returns ttl randomized by up to ±opts.Jitter of itself.
+func (c *Typed[K, V]) jitter(ttl time.Duration) time.Duration {
+	spread := time.Duration(float64(ttl) * c.opts.Jitter)
+	if spread <= 0 {
+		return ttl
+	}
+	return ttl - spread + rand.N(2*spread+1) //nolint:gosec // jitter needs no crypto randomness
+}
+
+// tags returns the tags of k's entry: its TypeTag followed by opts.Tags(k).
+// Returns errTooManyTags if there are more than maxTags.
+func (c *Typed[K, V]) tags(k K) ([]string, error) {
+	tags := []string{TypeTag(c.opts.Prefix)}
+	if c.opts.Tags != nil {
+		tags = append(tags, c.opts.Tags(k)...)
+	}
+	if len(tags) > maxTags {
+		return nil, fmt.Errorf("%w: %d", errTooManyTags, len(tags))
+	}
+	return tags, nil
+}
+
+// key builds the Redis key: "{prefix}:{Key(k)}".
+func (c *Typed[K, V]) key(k K) string {
+	return c.opts.Prefix + ":" + c.opts.Key(k)
+}
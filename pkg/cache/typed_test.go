@@ -0,0 +1,428 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+var testLogger = logger.New(&config.Config{LogLevel: "error"})
+
+// newTestRedis returns a RedisClient backed by an in-memory Redis server.
+func newTestRedis(t *testing.T) (*RedisClient, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	return &RedisClient{client: client, log: testLogger}, mr
+}
+
+type testValue struct {
+	Name string `json:"name"`
+}
+
+func newTestTyped(t *testing.T, opts TypedOptions[int, testValue]) (*Typed[int, testValue], *miniredis.Miniredis) {
+	t.Helper()
+	rc, mr := newTestRedis(t)
+	if opts.Prefix == "" {
+		opts.Prefix = "test"
+	}
+	if opts.TTL == 0 {
+		opts.TTL = time.Hour
+	}
+	return NewTyped(rc, opts), mr
+}
+
+func TestTyped_GetSetDelete(t *testing.T) {
+	c, mr := newTestTyped(t, TypedOptions[int, testValue]{})
+	ctx := context.Background()
+
+	if _, err := c.Get(ctx, 1); !errors.Is(err, ErrMiss) {
+		t.Fatalf("Get before Set: got %v, want ErrMiss", err)
+	}
+	if err := c.Set(ctx, 1, testValue{Name: "one"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if !mr.Exists("test:1") {
+		t.Fatalf("key test:1 not written; keys: %v", mr.Keys())
+	}
+	if ttl := mr.TTL("test:1"); ttl != time.Hour {
+		t.Errorf("TTL without jitter: got %v, want 1h", ttl)
+	}
+
+	got, err := c.Get(ctx, 1)
+	if err != nil || got.Name != "one" {
+		t.Fatalf("Get after Set: got %+v, %v", got, err)
+	}
+
+	if err := c.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := c.Get(ctx, 1); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get after Delete: got %v, want ErrMiss", err)
+	}
+}
+
+func TestTyped_Jitter(t *testing.T) {
+	c, mr := newTestTyped(t, TypedOptions[int, testValue]{TTL: 100 * time.Second, Jitter: 0.1})
+	ctx := context.Background()
+
+	seen := map[time.Duration]bool{}
+	for k := range 20 {
+		if err := c.Set(ctx, k, testValue{}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+		ttl := mr.TTL(c.key(k))
+		if ttl < 90*time.Second || ttl > 110*time.Second {
+			t.Errorf("TTL of %d: got %v, want 100s ±10%%", k, ttl)
+		}
+		seen[ttl] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("TTLs not randomized: %v", seen)
+	}
+}
+
+func TestTyped_GetOrLoad_NegativeCaching(t *testing.T) {
+	c, mr := newTestTyped(t, TypedOptions[int, testValue]{NegativeTTL: time.Minute})
+	ctx := context.Background()
+
+	var loads int
+	load := func(context.Context) (testValue, error) {
+		loads++
+		return testValue{}, ErrNotFound
+	}
+	for range 2 {
+		if _, err := c.GetOrLoad(ctx, 1, load); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetOrLoad: got %v, want ErrNotFound", err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loads: got %d, want 1", loads)
+	}
+	if ttl := mr.TTL("test:1"); ttl != time.Minute {
+		t.Errorf("negative TTL: got %v, want 1m", ttl)
+	}
+
+	// Without NegativeTTL the absence is not cached.
+	c.opts.NegativeTTL = 0
+	if _, err := c.GetOrLoad(ctx, 2, load); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetOrLoad: got %v, want ErrNotFound", err)
+	}
+	if mr.Exists("test:2") {
+		t.Error("absence cached with negative caching disabled")
+	}
+}
+
+func TestTyped_GetOrLoad_Singleflight(t *testing.T) {
+	c, _ := newTestTyped(t, TypedOptions[int, testValue]{})
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (testValue, error) {
+		loads.Add(1)
+		<-release
+		return testValue{Name: "loaded"}, nil
+	}
+
+	const callers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad(ctx, 1, load)
+			if err == nil && v.Name != "loaded" {
+				err = errors.New("unexpected value " + v.Name)
+			}
+			errs <- err
+		}()
+	}
+	// Give every caller time to miss and join the in-flight load.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetOrLoad: %v", err)
+		}
+	}
+	if n := loads.Load(); n != 1 {
+		t.Errorf("loads: got %d, want 1", n)
+	}
+	if v, err := c.Get(ctx, 1); err != nil || v.Name != "loaded" {
+		t.Errorf("cached value: got %+v, %v", v, err)
+	}
+}
+
+func TestTyped_GetOrLoad_RedisDown(t *testing.T) {
+	c, mr := newTestTyped(t, TypedOptions[int, testValue]{})
+	mr.Close()
+
+	v, err := c.GetOrLoad(context.Background(), 1, func(context.Context) (testValue, error) {
+		return testValue{Name: "db"}, nil
+	})
+	if err != nil || v.Name != "db" {
+		t.Errorf("GetOrLoad with Redis down: got %+v, %v; want the loaded value", v, err)
+	}
+}
+
+func TestTyped_GetOrLoad_CallerCancelled(t *testing.T) {
+	c, _ := newTestTyped(t, TypedOptions[int, testValue]{})
+	release := make(chan struct{})
+	loaded := make(chan error, 1)
+	load := func(ctx context.Context) (testValue, error) {
+		<-release
+		loaded <- ctx.Err()
+		return testValue{Name: "loaded"}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	if _, err := c.GetOrLoad(ctx, 1, load); !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetOrLoad: got %v, want context.Canceled", err)
+	}
+
+	// The load carries on for other callers and still fills the cache.
+	close(release)
+	if err := <-loaded; err != nil {
+		t.Errorf("load context: got %v, want it detached from the caller", err)
+	}
+	v, err := c.GetOrLoad(context.Background(), 1, load)
+	if err != nil || v.Name != "loaded" {
+		t.Errorf("GetOrLoad after load: got %+v, %v", v, err)
+	}
+}
+
+func TestItemCache(t *testing.T) {
+	rc, mr := newTestRedis(t)
+	c := NewItemCache(rc)
+	ctx := context.Background()
+
+	item := &CachedItem{
+		ID:        uuid.New(),
+		OrgID:     uuid.New(),
+		Name:      "widget",
+		CreatedAt: time.Date(2025, 1, 2, 3, 4, 5, 6, time.UTC),
+	}
+	if err := c.Set(ctx, item); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if key := "item:" + item.OrgID.String() + ":" + item.ID.String(); !mr.Exists(key) {
+		t.Fatalf("key %s not written; keys: %v", key, mr.Keys())
+	}
+
+	got, err := c.Get(ctx, item.OrgID, item.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if *got != *item {
+		t.Errorf("Get: got %+v, want %+v", got, item)
+	}
+	if _, err := c.Get(ctx, uuid.New(), item.ID); !errors.Is(err, ErrMiss) {
+		t.Errorf("Get from other org: got %v, want ErrMiss", err)
+	}
+
+	if err := c.Delete(ctx, item.OrgID, item.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	got, err = c.GetOrLoad(ctx, item.OrgID, item.ID, func(context.Context) (*CachedItem, error) {
+		return item, nil
+	})
+	if err != nil || *got != *item {
+		t.Errorf("GetOrLoad: got %+v, %v", got, err)
+	}
+}
+
+// lookupCounts collects cache.requests from reader, keyed "{tier} {result}".
+func lookupCounts(t *testing.T, reader sdkmetric.Reader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	counts := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "cache.requests" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				tier, _ := dp.Attributes.Value("tier")
+				result, _ := dp.Attributes.Value("result")
+				counts[tier.AsString()+" "+result.AsString()] += dp.Value
+			}
+		}
+	}
+	return counts
+}
+
+// waitUntil polls cond until it holds or a second has passed.
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTyped_LocalTier(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := newCacheMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	if err != nil {
+		t.Fatalf("newCacheMetrics: %v", err)
+	}
+
+	// Two instances sharing one Redis.
+	mr := miniredis.RunT(t)
+	newInstance := func() *Typed[int, testValue] {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		rc := &RedisClient{client: client, log: testLogger}
+		t.Cleanup(func() { _ = rc.Close() })
+		c := NewTyped(rc, TypedOptions[int, testValue]{Prefix: "test", TTL: time.Hour, LocalSize: 10})
+		c.metrics = m
+		return c
+	}
+	a, b := newInstance(), newInstance()
+	waitUntil(t, "both instances to subscribe", func() bool {
+		return mr.PubSubNumSub(invalidationChannel)[invalidationChannel] == 2
+	})
+	ctx := context.Background()
+
+	if err := a.Set(ctx, 1, testValue{Name: "v1"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	for range 2 {
+		if v, err := b.Get(ctx, 1); err != nil || v.Name != "v1" {
+			t.Fatalf("Get: got %+v, %v", v, err)
+		}
+	}
+	want := map[string]int64{"local miss": 1, "redis hit": 1, "local hit": 1}
+	if got := lookupCounts(t, reader); !maps.Equal(got, want) {
+		t.Errorf("lookups: got %v, want %v", got, want)
+	}
+
+	// A change on one instance evicts the key from the other's local tier.
+	if err := a.Set(ctx, 1, testValue{Name: "v2"}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	waitUntil(t, "b to see v2", func() bool {
+		v, err := b.Get(ctx, 1)
+		return err == nil && v.Name == "v2"
+	})
+
+	if err := a.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	waitUntil(t, "b to see the delete", func() bool {
+		_, err := b.Get(ctx, 1)
+		return errors.Is(err, ErrMiss)
+	})
+
+	// The local tier is bounded.
+	for k := range 20 {
+		if err := a.Set(ctx, k, testValue{}); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if n := a.local.len(); n != 10 {
+		t.Errorf("local entries: got %d, want 10", n)
+	}
+}
+
+func TestTyped_PublishesChangesOnly(t *testing.T) {
+	rc, _ := newTestRedis(t)
+	t.Cleanup(func() { _ = rc.Close() })
+	ctx := context.Background()
+	ps := rc.client.Subscribe(ctx, invalidationChannel)
+	t.Cleanup(func() { _ = ps.Close() })
+	if _, err := ps.Receive(ctx); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	msgs := ps.Channel()
+
+	shared := NewTyped(rc, TypedOptions[int, testValue]{Prefix: "shared", TTL: time.Hour})
+	local := NewTyped(rc, TypedOptions[int, testValue]{Prefix: "local", TTL: time.Hour, LocalSize: 10})
+	load := func(context.Context) (testValue, error) { return testValue{Name: "db"}, nil }
+
+	// Neither a cache without a local tier nor a read-through fill publishes.
+	if err := shared.Set(ctx, 1, testValue{}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := shared.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, c := range []*Typed[int, testValue]{shared, local} {
+		if _, err := c.GetOrLoad(ctx, 2, load); err != nil {
+			t.Fatalf("GetOrLoad: %v", err)
+		}
+	}
+
+	if err := local.Set(ctx, 1, testValue{}); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := local.Delete(ctx, 3); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	for _, want := range []string{"local:1", "local:3"} {
+		select {
+		case msg := <-msgs:
+			if got := msg.Payload[strings.IndexByte(msg.Payload, ' ')+1:]; got != want {
+				t.Errorf("published %q, want %q", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for the invalidation of %s", want)
+		}
+	}
+}
+
+func TestInvalidator_IgnoresOwnMessages(t *testing.T) {
+	local := newLRU(10, time.Minute)
+	inv := newInvalidator(nil)
+	inv.locals["test:"] = local
+	local.add("test:1", []byte("v"))
+	local.add("other:1", []byte("v"))
+
+	inv.handle(inv.origin + " test:1")
+	if _, ok := local.get("test:1"); !ok {
+		t.Error("own invalidation evicted the key")
+	}
+	inv.handle("peer test:1 other:1")
+	if _, ok := local.get("test:1"); ok {
+		t.Error("peer invalidation did not evict the key")
+	}
+	if _, ok := local.get("other:1"); !ok {
+		t.Error("key of another cache evicted")
+	}
+
+	local.add("test:2", []byte("v"), "org:a")
+	inv.handle("peer #org:a")
+	if _, ok := local.get("test:2"); ok {
+		t.Error("peer tag invalidation did not evict the tagged key")
+	}
+}
@@ -0,0 +1,161 @@
+// Package database provides the PostgreSQL connection pool shared by services
+// and the transaction helpers repositories write through.
+//
+// WithTx runs a function in a transaction, committing if it returns nil and
+// rolling back otherwise. Transactions that fail with a serialization failure
+// or deadlock (SQLSTATE 40001, 40P01) are retried from the start, so the
+// function must be safe to run more than once.
+//
+// Transactions are carried in the context. A WithTx call whose context
+// already holds a transaction joins it through a savepoint instead of
+// starting a new one, so repositories compose into a larger unit of work:
+//
+//	err := db.WithTxContext(ctx, func(ctx context.Context, _ *sql.Tx) error {
+//	    if err := items.Save(ctx, item); err != nil { // joins via SAVEPOINT
+//	        return err
+//	    }
+//	    return audit.Record(ctx, entry)
+//	})
+//
+// Reads use Conn, which returns the context's transaction when there is one
+// and the pool otherwise, or Reader, which routes reads that tolerate
+// replication lag to read replicas when configured. UnitOfWork builds on this to also collect the domain
+// events raised by those repositories and publish them in the transaction.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" database/sql driver
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// pingTimeout bounds the connectivity check in NewPool.
+const pingTimeout = 5 * time.Second
+
+// Querier is the subset of *sql.DB and *sql.Tx used by sqlc-generated queries.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+)
+
+// Database is a PostgreSQL connection pool with transaction helpers, and
+// optionally read replicas.
+type Database struct {
+	db         *sql.DB
+	log        logger.Logger
+	maxRetries int // WithTx retries after the first attempt
+
+	replicas   []*replica
+	nextRead   atomic.Uint64 // round-robin position in replicas
+	closing    chan struct{} // closed by Close to stop replica health checks
+	checksDone chan struct{} // closed when the health check loop has exited
+}
+
+// NewPool opens a connection pool to cfg.DefinitionDatabaseURL, sized and
+// recycled according to the cfg.Database* settings, and verifies connectivity.
+//
+// Each of cfg.DefinitionDatabaseReplicaURLs gets a pool of the same size for
+// Reader. Replicas are pinged every cfg.DatabaseReplicaCheckInterval and only
+// used while reachable; one that is down at startup is logged, not fatal.
+func NewPool(ctx context.Context, cfg *config.Config, log logger.Logger) (*Database, error) {
+	db, err := openPool(cfg.DefinitionDatabaseURL, cfg)
+	if err != nil {
+		return nil, err
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("database: ping: %w", err)
+	}
+
+	d := New(db, log)
+	d.maxRetries = cfg.DatabaseTxMaxRetries
+	for i, url := range splitURLs(cfg.DefinitionDatabaseReplicaURLs) {
+		rdb, err := openPool(url, cfg)
+		if err != nil {
+			_ = d.Close()
+			return nil, fmt.Errorf("database: replica %d: %w", i, err)
+		}
+		d.replicas = append(d.replicas, &replica{db: rdb})
+	}
+	if len(d.replicas) > 0 {
+		d.checkReplicas(ctx)
+	}
+	if len(d.replicas) > 0 && cfg.DatabaseReplicaCheckInterval > 0 {
+		d.closing = make(chan struct{})
+		d.checksDone = make(chan struct{})
+		go d.runReplicaChecks(cfg.DatabaseReplicaCheckInterval)
+	}
+	return d, nil
+}
+
+// openPool opens a pool to url configured from the cfg.Database* settings.
+func openPool(url string, cfg *config.Config) (*sql.DB, error) {
+	db, err := sql.Open("pgx", url)
+	if err != nil {
+		return nil, fmt.Errorf("database: open: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.DatabaseMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DatabaseMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DatabaseConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.DatabaseConnMaxIdleTime)
+	return db, nil
+}
+
+// New wraps an open *sql.DB with DefaultTxMaxRetries.
+func New(db *sql.DB, log logger.Logger) *Database {
+	return &Database{db: db, log: log, maxRetries: DefaultTxMaxRetries}
+}
+
+// DB returns the primary pool, for components that manage their own
+// transactions (e.g. the outbox relay).
+func (d *Database) DB() *sql.DB {
+	return d.db
+}
+
+// Conn returns the transaction carried by ctx, or the primary if there is none.
+// Pass it to queries that should see the writes of an enclosing unit of work.
+func (d *Database) Conn(ctx context.Context) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return d.db
+}
+
+// Ping checks the health of the connection to the primary.
+func (d *Database) Ping(ctx context.Context) error {
+	if err := d.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database: ping: %w", err)
+	}
+	return nil
+}
+
+// Close stops replica health checks and closes all pools, waiting for in-use
+// connections to be returned.
+func (d *Database) Close() error {
+	if d.closing != nil {
+		close(d.closing)
+		<-d.checksDone
+	}
+	errs := []error{d.db.Close()}
+	for _, r := range d.replicas {
+		errs = append(errs, r.db.Close())
+	}
+	return errors.Join(errs...)
+}
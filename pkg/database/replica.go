@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// replica is a read-only pool and whether its last health check passed.
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// Reader returns a connection for read-only queries that tolerate replication
+// lag: the transaction carried by ctx if any, the primary if ctx observed a
+// write (see WithReadYourWrites) or asks for it (see WithPrimary), and otherwise the next healthy replica in
+// round-robin order. With no replica configured or healthy, it returns the
+// primary.
+func (d *Database) Reader(ctx context.Context) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if wrote(ctx) || onPrimary(ctx) {
+		return d.db
+	}
+	if r := d.nextReplica(); r != nil {
+		return r.db
+	}
+	return d.db
+}
+
+// nextReplica returns the next healthy replica after the last one handed
+// out, or nil if there is none.
+func (d *Database) nextReplica() *replica {
+	n := uint64(len(d.replicas))
+	if n == 0 {
+		return nil
+	}
+	// Skipped replicas consume a turn too, so the load of one that is down
+	// spreads over all healthy ones rather than falling on its successor.
+	for range n {
+		if r := d.replicas[d.nextRead.Add(1)%n]; r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// checkReplicas pings each replica and takes it in or out of rotation,
+// logging every change.
+func (d *Database) checkReplicas(ctx context.Context) {
+	for i, r := range d.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+
+		if was := r.healthy.Swap(err == nil); was != (err == nil) {
+			if err != nil {
+				d.log.WarnContext(ctx, "database: replica unhealthy, reading from primary instead",
+					"replica", i, "error", err)
+			} else {
+				d.log.InfoContext(ctx, "database: replica healthy", "replica", i)
+			}
+		}
+	}
+}
+
+// runReplicaChecks calls checkReplicas every interval until Close.
+func (d *Database) runReplicaChecks(interval time.Duration) {
+	defer close(d.checksDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.closing:
+			return
+		case <-ticker.C:
+			d.checkReplicas(context.Background())
+		}
+	}
+}
+
+// splitURLs parses a comma-separated list of database URLs.
+func splitURLs(s string) []string {
+	var urls []string
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	return urls
+}
+
+type writesContextKey struct{}
+
+// WithReadYourWrites returns a copy of ctx in which Reader switches to the
+// primary once a write committed under it, so a request reads back what it
+// just wrote rather than a replica that may not have replayed it yet.
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(writesContextKey{}).(*atomic.Bool); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, writesContextKey{}, new(atomic.Bool))
+}
+
+// ReadYourWrites is HTTP middleware that applies WithReadYourWrites to each
+// request's context.
+func ReadYourWrites(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithReadYourWrites(r.Context())))
+	})
+}
+
+// MarkWritten records a write on ctx, sending its later Reader calls to the
+// primary. Transactions committed by WithTx record themselves; call it after
+// writes made directly through DB or Conn.
+func MarkWritten(ctx context.Context) {
+	if flag, ok := ctx.Value(writesContextKey{}).(*atomic.Bool); ok {
+		flag.Store(true)
+	}
+}
+
+// wrote reports whether a write was recorded on ctx.
+func wrote(ctx context.Context) bool {
+	flag, ok := ctx.Value(writesContextKey{}).(*atomic.Bool)
+	return ok && flag.Load()
+}
+
+type primaryContextKey struct{}
+
+// WithPrimary returns a copy of ctx in which Reader always returns the primary.
+// Use it for reads whose result outlives the request, such as cache fills,
+// where a lagging replica would keep serving stale data after the next
+// eviction.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+// onPrimary reports whether ctx asks Reader for the primary.
+func onPrimary(ctx context.Context) bool {
+	return ctx.Value(primaryContextKey{}) != nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// downConnector is a driver whose connections always fail, like an
+// unreachable server.
+type downConnector struct{}
+
+func (downConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("connection refused")
+}
+func (downConnector) Driver() driver.Driver { return nil }
+
+// addReplica adds a replica backed by c to d and returns its pool.
+func addReplica(t *testing.T, d *Database, c driver.Connector, healthy bool) *sql.DB {
+	t.Helper()
+	db := sql.OpenDB(c)
+	t.Cleanup(func() { _ = db.Close() })
+	r := &replica{db: db}
+	r.healthy.Store(healthy)
+	d.replicas = append(d.replicas, r)
+	return db
+}
+
+func TestReader_RoundRobin(t *testing.T) {
+	d, _ := newTestDatabase(t)
+	ctx := context.Background()
+	if got := d.Reader(ctx); got != d.DB() {
+		t.Fatalf("without replicas: got %p, want the primary", got)
+	}
+
+	r1 := addReplica(t, d, &recorder{}, true)
+	addReplica(t, d, &recorder{}, false)
+	r3 := addReplica(t, d, &recorder{}, true)
+
+	counts := map[Querier]int{}
+	for range 6 {
+		counts[d.Reader(ctx)]++
+	}
+	if counts[r1] != 3 || counts[r3] != 3 {
+		t.Errorf("reads per replica: got r1=%d r3=%d (others %d), want 3 each",
+			counts[r1], counts[r3], 6-counts[r1]-counts[r3])
+	}
+
+	for _, r := range d.replicas {
+		r.healthy.Store(false)
+	}
+	if got := d.Reader(ctx); got != d.DB() {
+		t.Errorf("all replicas down: got %p, want the primary", got)
+	}
+}
+
+func TestReader_ReadYourWrites(t *testing.T) {
+	d, _ := newTestDatabase(t)
+	replicaDB := addReplica(t, d, &recorder{}, true)
+	ctx := WithReadYourWrites(context.Background())
+
+	if got := d.Reader(ctx); got != replicaDB {
+		t.Fatalf("before a write: got %p, want the replica", got)
+	}
+	err := d.WithTx(ctx, func(*sql.Tx) error { return nil }, ReadOnly())
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if got := d.Reader(ctx); got != replicaDB {
+		t.Fatalf("after a read-only transaction: got %p, want the replica", got)
+	}
+
+	err = d.WithTxContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if got := d.Reader(ctx); got != tx {
+			t.Errorf("in transaction: got %p, want the transaction", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: %v", err)
+	}
+	if got := d.Reader(ctx); got != d.DB() {
+		t.Errorf("after a write: got %p, want the primary", got)
+	}
+	if got := d.Reader(context.Background()); got != replicaDB {
+		t.Errorf("other context after a write: got %p, want the replica", got)
+	}
+}
+
+func TestReader_WithPrimary(t *testing.T) {
+	d, _ := newTestDatabase(t)
+	addReplica(t, d, &recorder{}, true)
+
+	if got := d.Reader(WithPrimary(context.Background())); got != d.DB() {
+		t.Errorf("got %p, want the primary", got)
+	}
+}
+
+func TestReadYourWrites_Middleware(t *testing.T) {
+	d, _ := newTestDatabase(t)
+	addReplica(t, d, &recorder{}, true)
+
+	var got Querier
+	h := ReadYourWrites(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		if err := d.WithTx(r.Context(), func(*sql.Tx) error { return nil }); err != nil {
+			t.Errorf("WithTx: %v", err)
+		}
+		got = d.Reader(r.Context())
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if got != d.DB() {
+		t.Errorf("read after write in request: got %p, want the primary", got)
+	}
+}
+
+func TestCheckReplicas(t *testing.T) {
+	d, _ := newTestDatabase(t)
+	addReplica(t, d, &recorder{}, false)
+	addReplica(t, d, downConnector{}, true)
+
+	d.checkReplicas(context.Background())
+	if !d.replicas[0].healthy.Load() {
+		t.Error("reachable replica: not taken into rotation")
+	}
+	if d.replicas[1].healthy.Load() {
+		t.Error("unreachable replica: not taken out of rotation")
+	}
+}
+
+func TestSplitURLs(t *testing.T) {
+	got := splitURLs(" postgres://a/db , ,postgres://b/db")
+	if len(got) != 2 || got[0] != "postgres://a/db" || got[1] != "postgres://b/db" {
+		t.Errorf("splitURLs: got %q", got)
+	}
+	if got := splitURLs(""); len(got) != 0 {
+		t.Errorf("splitURLs(\"\"): got %q, want none", got)
+	}
+}
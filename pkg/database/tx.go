@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DefaultTxMaxRetries is how often WithTx retries a transaction when the pool
+// was not created from a config.
+const DefaultTxMaxRetries = 3
+
+// Backoff between transaction attempts: doubling from txRetryBaseDelay up to
+// txRetryMaxDelay, each wait randomized to between half and all of its length.
+const (
+	txRetryBaseDelay = 10 * time.Millisecond
+	txRetryMaxDelay  = time.Second
+)
+
+// SQLSTATEs after which a whole transaction is retried.
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// TxOption configures the transaction started by WithTx.
+type TxOption func(*sql.TxOptions)
+
+// WithIsolation runs the transaction at level instead of the server default
+// (READ COMMITTED). Serializable transactions fail with 40001 under
+// contention; WithTx retries them.
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *sql.TxOptions) { o.Isolation = level }
+}
+
+// ReadOnly starts a READ ONLY transaction, in which writes fail.
+func ReadOnly() TxOption {
+	return func(o *sql.TxOptions) { o.ReadOnly = true }
+}
+
+type txContextKey struct{}
+
+// txState is the transaction carried by a context and how many savepoints
+// deep the current WithTx call runs.
+type txState struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// TxFromContext returns the transaction carried by ctx, if any.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	st, ok := ctx.Value(txContextKey{}).(txState)
+	return st.tx, ok
+}
+
+// ContextWithTx returns a copy of ctx carrying tx, so WithTx and Conn calls
+// under it join tx. Use it for transactions started outside this package.
+func ContextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txContextKey{}, txState{tx: tx})
+}
+
+// IsRetryable reports whether err is a serialization failure or deadlock,
+// after which the transaction can succeed if run again.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == sqlStateSerializationFailure || pgErr.Code == sqlStateDeadlockDetected
+}
+
+// WithTx runs fn in a transaction: committed if fn returns nil, rolled back
+// if it returns an error or panics. fn's error is returned unchanged.
+//
+// If ctx carries a transaction, fn runs in it within a savepoint, which is
+// released on success and rolled back to on failure; opts are ignored, as the
+// outermost transaction sets them. Otherwise a transaction is started with
+// opts, and retried up to the configured number of times with backoff when it
+// fails with a serialization failure or deadlock.
+//
+// Use WithTxContext when fn calls code that should join the transaction.
+func (d *Database) WithTx(ctx context.Context, fn func(tx *sql.Tx) error, opts ...TxOption) error {
+	return d.WithTxContext(ctx, func(_ context.Context, tx *sql.Tx) error {
+		return fn(tx)
+	}, opts...)
+}
+
+// WithTxContext is WithTx for functions that pass the transaction on: fn's
+// context carries it, so WithTx and Conn calls made with that context, such
+// as other repositories', join the same transaction.
+func (d *Database) WithTxContext(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, opts ...TxOption) error {
+	if st, ok := ctx.Value(txContextKey{}).(txState); ok {
+		return withSavepoint(ctx, st, fn)
+	}
+
+	var o sql.TxOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for attempt := 1; ; attempt++ {
+		err := d.runTx(ctx, &o, fn)
+		if err == nil || !IsRetryable(err) || attempt > d.maxRetries {
+			return err
+		}
+
+		delay := txRetryDelay(attempt)
+		d.log.WarnContext(ctx, "database: transaction conflict, retrying",
+			"attempt", attempt, "retry_in", delay, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runTx runs fn in a new transaction with opts.
+func (d *Database) runTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("database: begin tx: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(ContextWithTx(ctx, tx), tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			d.log.ErrorContext(ctx, "database: rollback failed", "error", rbErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("database: commit tx: %w", err)
+	}
+	if !opts.ReadOnly {
+		MarkWritten(ctx)
+	}
+	return nil
+}
+
+// withSavepoint runs fn in a savepoint of the transaction carried by ctx.
+func withSavepoint(ctx context.Context, st txState, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	name := fmt.Sprintf("sp_%d", st.depth+1)
+	if _, err := st.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("database: savepoint %s: %w", name, err)
+	}
+	// Roll back even when ctx is cancelled, so the outer transaction stays usable.
+	rollback := func() error {
+		_, err := st.tx.ExecContext(context.WithoutCancel(ctx), "ROLLBACK TO SAVEPOINT "+name)
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = rollback()
+			panic(p)
+		}
+	}()
+
+	inner := context.WithValue(ctx, txContextKey{}, txState{tx: st.tx, depth: st.depth + 1})
+	if err := fn(inner, st.tx); err != nil {
+		if rbErr := rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("database: rollback to savepoint %s: %w", name, rbErr))
+		}
+		return err
+	}
+	if _, err := st.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("database: release savepoint %s: %w", name, err)
+	}
+	return nil
+}
+
+// txRetryDelay returns the wait after the given failed attempt (1-based).
+func txRetryDelay(attempt int) time.Duration {
+	d := min(txRetryBaseDelay<<min(attempt-1, 10), txRetryMaxDelay)
+	return d/2 + rand.N(d/2+1) //nolint:gosec // jitter needs no crypto randomness
+}
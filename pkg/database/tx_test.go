@@ -0,0 +1,251 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// recorder is a database/sql driver that records the statements it is sent
+// and fails commits with the queued errors.
+type recorder struct {
+	mu         sync.Mutex
+	log        []string
+	commitErrs []error
+}
+
+func (r *recorder) record(stmt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.log = append(r.log, stmt)
+}
+
+func (r *recorder) statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.log...)
+}
+
+func (r *recorder) Connect(context.Context) (driver.Conn, error) { return recorderConn{r}, nil }
+func (r *recorder) Driver() driver.Driver                        { return nil }
+
+type recorderConn struct{ r *recorder }
+
+func (c recorderConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c recorderConn) Close() error                        { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c recorderConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	stmt := "BEGIN"
+	if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+		stmt += " " + sql.IsolationLevel(opts.Isolation).String()
+	}
+	if opts.ReadOnly {
+		stmt += " READ ONLY"
+	}
+	c.r.record(stmt)
+	return recorderTx(c), nil
+}
+
+func (c recorderConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+type recorderTx struct{ r *recorder }
+
+func (t recorderTx) Commit() error {
+	t.r.mu.Lock()
+	var err error
+	if len(t.r.commitErrs) > 0 {
+		err, t.r.commitErrs = t.r.commitErrs[0], t.r.commitErrs[1:]
+	}
+	t.r.mu.Unlock()
+	if err != nil {
+		t.r.record("COMMIT failed")
+		return err
+	}
+	t.r.record("COMMIT")
+	return nil
+}
+
+func (t recorderTx) Rollback() error {
+	t.r.record("ROLLBACK")
+	return nil
+}
+
+func newTestDatabase(t *testing.T) (*Database, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	db := sql.OpenDB(rec)
+	t.Cleanup(func() { _ = db.Close() })
+	return New(db, logger.New(&config.Config{LogLevel: "error"})), rec
+}
+
+var errSerialization = &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+
+func TestWithTx(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name       string
+		commitErrs []error
+		run        func(ctx context.Context, d *Database) error
+		wantErr    error
+		want       []string
+	}{
+		{
+			name: "commit",
+			run: func(ctx context.Context, d *Database) error {
+				return d.WithTx(ctx, func(*sql.Tx) error { return nil })
+			},
+			want: []string{"BEGIN", "COMMIT"},
+		},
+		{
+			name: "rollback on error",
+			run: func(ctx context.Context, d *Database) error {
+				return d.WithTx(ctx, func(*sql.Tx) error { return errFailed })
+			},
+			wantErr: errFailed,
+			want:    []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			name: "options",
+			run: func(ctx context.Context, d *Database) error {
+				return d.WithTx(ctx, func(*sql.Tx) error { return nil },
+					WithIsolation(sql.LevelSerializable), ReadOnly())
+			},
+			want: []string{"BEGIN Serializable READ ONLY", "COMMIT"},
+		},
+		{
+			name: "retry serialization failure",
+			run: func(ctx context.Context, d *Database) error {
+				calls := 0
+				return d.WithTx(ctx, func(*sql.Tx) error {
+					if calls++; calls == 1 {
+						return fmt.Errorf("insert: %w", errSerialization)
+					}
+					return nil
+				})
+			},
+			want: []string{"BEGIN", "ROLLBACK", "BEGIN", "COMMIT"},
+		},
+		{
+			name:       "retry failed commit",
+			commitErrs: []error{&pgconn.PgError{Code: "40P01"}},
+			run: func(ctx context.Context, d *Database) error {
+				return d.WithTx(ctx, func(*sql.Tx) error { return nil })
+			},
+			want: []string{"BEGIN", "COMMIT failed", "BEGIN", "COMMIT"},
+		},
+		{
+			name: "retries exhausted",
+			run: func(ctx context.Context, d *Database) error {
+				d.maxRetries = 1
+				return d.WithTx(ctx, func(*sql.Tx) error { return errSerialization })
+			},
+			wantErr: errSerialization,
+			want:    []string{"BEGIN", "ROLLBACK", "BEGIN", "ROLLBACK"},
+		},
+		{
+			name: "nested savepoints",
+			run: func(ctx context.Context, d *Database) error {
+				return d.WithTxContext(ctx, func(ctx context.Context, _ *sql.Tx) error {
+					if err := d.WithTxContext(ctx, func(ctx context.Context, _ *sql.Tx) error {
+						return d.WithTx(ctx, func(*sql.Tx) error { return nil })
+					}); err != nil {
+						return err
+					}
+					// A failed inner unit is undone without aborting the outer one.
+					if err := d.WithTx(ctx, func(*sql.Tx) error { return errFailed }); !errors.Is(err, errFailed) {
+						return fmt.Errorf("inner error: got %v", err)
+					}
+					return nil
+				})
+			},
+			want: []string{
+				"BEGIN",
+				"SAVEPOINT sp_1", "SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_2", "RELEASE SAVEPOINT sp_1",
+				"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1",
+				"COMMIT",
+			},
+		},
+		{
+			name: "nested serialization failure retries outer transaction",
+			run: func(ctx context.Context, d *Database) error {
+				calls := 0
+				return d.WithTxContext(ctx, func(ctx context.Context, _ *sql.Tx) error {
+					return d.WithTx(ctx, func(*sql.Tx) error {
+						if calls++; calls == 1 {
+							return errSerialization
+						}
+						return nil
+					})
+				})
+			},
+			want: []string{
+				"BEGIN", "SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1", "ROLLBACK",
+				"BEGIN", "SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1", "COMMIT",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, rec := newTestDatabase(t)
+			rec.commitErrs = tt.commitErrs
+
+			err := tt.run(context.Background(), d)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error: got %v, want %v", err, tt.wantErr)
+			}
+			if got := rec.statements(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements:\n got  %q\n want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConn verifies Conn returns the context's transaction, and the pool otherwise.
+func TestConn(t *testing.T) {
+	d, _ := newTestDatabase(t)
+	ctx := context.Background()
+
+	if got := d.Conn(ctx); got != d.DB() {
+		t.Errorf("without transaction: got %T, want the pool", got)
+	}
+	err := d.WithTxContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if got := d.Conn(ctx); got != tx {
+			t.Errorf("in transaction: got %T, want the transaction", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTxContext: %v", err)
+	}
+}
+
+// TestWithTx_Panic verifies a panicking function rolls back and re-panics.
+func TestWithTx_Panic(t *testing.T) {
+	d, rec := newTestDatabase(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic was swallowed")
+		}
+		if got, want := rec.statements(), []string{"BEGIN", "ROLLBACK"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("statements: got %q, want %q", got, want)
+		}
+	}()
+	_ = d.WithTx(context.Background(), func(*sql.Tx) error { panic("boom") })
+}
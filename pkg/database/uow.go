@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// TxPublisherFactory creates publishers bound to a transaction, so messages
+// are committed or rolled back with it. events.Bus implements it.
+type TxPublisherFactory interface {
+	NewTxPublisher(tx *sql.Tx) (message.Publisher, error)
+}
+
+// UnitOfWork groups the writes of several repositories, and the domain events
+// they raise, into a single transaction.
+//
+// Run starts the transaction and carries it, with an event collector, in the
+// context passed to fn. Repositories join it through WithTx and Conn, and
+// hand their events to Collect instead of publishing them. When fn returns
+// nil the collected events are published through a publisher bound to the
+// transaction, which then commits, so data and events become visible together
+// or not at all:
+//
+//	err := uow.Run(ctx, func(ctx context.Context) error {
+//	    if err := items.Update(ctx, item); err != nil {
+//	        return err
+//	    }
+//	    return quotas.Consume(ctx, item.OrgID, 1)
+//	})
+type UnitOfWork struct {
+	db  *Database
+	pub TxPublisherFactory
+}
+
+// NewUnitOfWork returns a UnitOfWork that runs transactions on db and
+// publishes collected events through pub.
+func NewUnitOfWork(db *Database, pub TxPublisherFactory) *UnitOfWork {
+	return &UnitOfWork{db: db, pub: pub}
+}
+
+type uowContextKey struct{}
+
+// collectedEvent is a message waiting for its unit of work to commit.
+type collectedEvent struct {
+	topic string
+	msg   *message.Message
+}
+
+// uowState holds the events collected by the outermost Run of a context.
+type uowState struct {
+	events []collectedEvent
+}
+
+// Run calls fn in a transaction started with opts and publishes the events
+// collected during it before committing. Retries, rollback and panics behave
+// as in WithTx; collected events are discarded with each rolled-back attempt.
+//
+// Run nested inside another unit of work, or inside a transaction carried by
+// ctx, joins it through a savepoint. Its events are published by the
+// outermost Run, and dropped if the nested Run fails.
+func (u *UnitOfWork) Run(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if st, ok := ctx.Value(uowContextKey{}).(*uowState); ok {
+		mark := len(st.events)
+		return u.db.WithTxContext(ctx, func(ctx context.Context, _ *sql.Tx) error {
+			if err := fn(ctx); err != nil {
+				st.events = st.events[:mark]
+				return err
+			}
+			return nil
+		}, opts...)
+	}
+
+	return u.db.WithTxContext(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		st := &uowState{}
+		if err := fn(context.WithValue(ctx, uowContextKey{}, st)); err != nil {
+			return err
+		}
+		return u.publish(ctx, tx, st.events)
+	}, opts...)
+}
+
+// publish writes events to their topics through a publisher bound to tx.
+func (u *UnitOfWork) publish(ctx context.Context, tx *sql.Tx, events []collectedEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if u.pub == nil {
+		return fmt.Errorf("database: %d events collected without a publisher", len(events))
+	}
+	p, err := u.pub.NewTxPublisher(tx)
+	if err != nil {
+		return fmt.Errorf("database: create tx publisher: %w", err)
+	}
+	for _, e := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := p.Publish(e.topic, e.msg); err != nil { //nolint:contextcheck
+			return fmt.Errorf("database: publish to %s: %w", e.topic, err)
+		}
+	}
+	return nil
+}
+
+// Collect records msg for publication to topic when the unit of work carried
+// by ctx commits. It reports false, recording nothing, if ctx carries none;
+// the caller then publishes msg itself. Collect is not safe for concurrent
+// use within one unit of work.
+func Collect(ctx context.Context, topic string, msg *message.Message) bool {
+	st, ok := ctx.Value(uowContextKey{}).(*uowState)
+	if !ok {
+		return false
+	}
+	st.events = append(st.events, collectedEvent{topic: topic, msg: msg})
+	return true
+}
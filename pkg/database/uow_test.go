@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// recordingPublisher records published topics in the driver's statement log,
+// so their order relative to SAVEPOINT and COMMIT can be checked.
+type recordingPublisher struct {
+	rec *recorder
+	err error
+}
+
+func (p recordingPublisher) NewTxPublisher(*sql.Tx) (message.Publisher, error) { return p, nil }
+func (p recordingPublisher) Close() error                                      { return nil }
+
+func (p recordingPublisher) Publish(topic string, msgs ...*message.Message) error {
+	if p.err != nil {
+		return p.err
+	}
+	for _, msg := range msgs {
+		p.rec.record("PUBLISH " + topic + " " + string(msg.Payload))
+	}
+	return nil
+}
+
+// collect records an event named payload on topic "item".
+func collect(ctx context.Context, t *testing.T, payload string) {
+	t.Helper()
+	if !Collect(ctx, "item", message.NewMessage(payload, []byte(payload))) {
+		t.Fatalf("Collect(%s): no unit of work in context", payload)
+	}
+}
+
+func TestUnitOfWork(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		pubErr  error
+		run     func(ctx context.Context, t *testing.T, u *UnitOfWork) error
+		wantErr error
+		want    []string
+	}{
+		{
+			name: "publish collected events before commit",
+			run: func(ctx context.Context, t *testing.T, u *UnitOfWork) error {
+				return u.Run(ctx, func(ctx context.Context) error {
+					collect(ctx, t, "a")
+					return u.db.WithTx(ctx, func(*sql.Tx) error {
+						collect(ctx, t, "b")
+						return nil
+					})
+				})
+			},
+			want: []string{
+				"BEGIN", "SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1",
+				"PUBLISH item a", "PUBLISH item b", "COMMIT",
+			},
+		},
+		{
+			name: "discard events on error",
+			run: func(ctx context.Context, t *testing.T, u *UnitOfWork) error {
+				return u.Run(ctx, func(ctx context.Context) error {
+					collect(ctx, t, "a")
+					return errFailed
+				})
+			},
+			wantErr: errFailed,
+			want:    []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			name:   "roll back when publishing fails",
+			pubErr: errFailed,
+			run: func(ctx context.Context, t *testing.T, u *UnitOfWork) error {
+				return u.Run(ctx, func(ctx context.Context) error {
+					collect(ctx, t, "a")
+					return nil
+				})
+			},
+			wantErr: errFailed,
+			want:    []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			name: "drop events of failed nested run",
+			run: func(ctx context.Context, t *testing.T, u *UnitOfWork) error {
+				return u.Run(ctx, func(ctx context.Context) error {
+					collect(ctx, t, "a")
+					_ = u.Run(ctx, func(ctx context.Context) error {
+						collect(ctx, t, "b")
+						return errFailed
+					})
+					return u.Run(ctx, func(ctx context.Context) error {
+						collect(ctx, t, "c")
+						return nil
+					})
+				})
+			},
+			want: []string{
+				"BEGIN",
+				"SAVEPOINT sp_1", "ROLLBACK TO SAVEPOINT sp_1",
+				"SAVEPOINT sp_1", "RELEASE SAVEPOINT sp_1",
+				"PUBLISH item a", "PUBLISH item c", "COMMIT",
+			},
+		},
+		{
+			name: "publish once after retry",
+			run: func(ctx context.Context, t *testing.T, u *UnitOfWork) error {
+				calls := 0
+				return u.Run(ctx, func(ctx context.Context) error {
+					collect(ctx, t, "a")
+					if calls++; calls == 1 {
+						return errSerialization
+					}
+					return nil
+				})
+			},
+			want: []string{"BEGIN", "ROLLBACK", "BEGIN", "PUBLISH item a", "COMMIT"},
+		},
+		{
+			name: "join transaction from context",
+			run: func(ctx context.Context, t *testing.T, u *UnitOfWork) error {
+				return u.db.WithTxContext(ctx, func(ctx context.Context, _ *sql.Tx) error {
+					return u.Run(ctx, func(ctx context.Context) error {
+						collect(ctx, t, "a")
+						return nil
+					})
+				})
+			},
+			want: []string{"BEGIN", "SAVEPOINT sp_1", "PUBLISH item a", "RELEASE SAVEPOINT sp_1", "COMMIT"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, rec := newTestDatabase(t)
+			u := NewUnitOfWork(d, recordingPublisher{rec: rec, err: tt.pubErr})
+
+			err := tt.run(context.Background(), t, u)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error: got %v, want %v", err, tt.wantErr)
+			}
+			if got := rec.statements(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("statements:\n got  %q\n want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollect_NoUnitOfWork(t *testing.T) {
+	if Collect(context.Background(), "item", message.NewMessage("a", nil)) {
+		t.Error("Collect outside a unit of work: got true, want false")
+	}
+}
@@ -0,0 +1,146 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// Bus is the event bus contract services depend on. EventBus (PostgreSQL) and
+// MemoryBus (in-process, for tests and local development) implement it with the
+// same delivery semantics: trace propagation, retries with backoff and
+// dead-lettering of exhausted messages. See EventBus.Subscribe for details.
+type Bus interface {
+	// Publish sends msgs to topic, injecting the OTel trace context from ctx.
+	Publish(ctx context.Context, topic string, msgs ...*message.Message) error
+	// Subscribe processes messages from topic with handler until ctx is
+	// cancelled or the bus is closed. The returned channel must be drained.
+	Subscribe(ctx context.Context, topic string, handler func(context.Context, *message.Message) error, opts ...SubscribeOption) (<-chan error, error)
+	// NewTxPublisher returns a publisher bound to tx for atomic "save + publish".
+	NewTxPublisher(tx *sql.Tx) (message.Publisher, error)
+	// Ping reports whether the bus is usable.
+	Ping(ctx context.Context) error
+	// Close stops subscriptions and waits for in-flight handlers.
+	Close() error
+}
+
+var (
+	_ Bus = (*EventBus)(nil)
+	_ Bus = (*MemoryBus)(nil)
+)
+
+// injectTrace writes the OTel trace context from ctx into each message's metadata.
+func injectTrace(ctx context.Context, msgs []*message.Message) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	for _, msg := range msgs {
+		for k, v := range carrier {
+			msg.Metadata.Set(k, v)
+		}
+	}
+}
+
+// deliver runs the delivery loop shared by all Bus implementations until msgs
+// is closed: skip messages outside part, restore the publisher's trace, call
+// handler with any CloudEvent envelope removed through cfg's middleware with
+// cfg's retry policy, dead-letter failed messages through dlq, and Ack/Nack.
+// Errors are forwarded to the returned channel (capacity 100), which is
+// closed when the loop exits.
+func deliver(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	log logger.Logger,
+	dlq message.Publisher,
+	metrics *busMetrics,
+	topic, group, name string,
+	msgs <-chan *message.Message,
+	handler Handler,
+	cfg subscribeConfig,
+	part partition,
+) <-chan error {
+	errCh := make(chan error, 100)
+	propagator := otel.GetTextMapPropagator()
+	handler = withAttemptTimeout(chain(withCloudEvents(handler), cfg.middleware), cfg.timeout)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(errCh)
+
+		for msg := range msgs {
+			if !part.owns(msg) {
+				msg.Ack() // handled by the partition that owns its key
+				continue
+			}
+
+			// Restore the publisher's trace context from message metadata.
+			carrier := propagation.MapCarrier{}
+			for k, v := range msg.Metadata {
+				carrier[k] = v
+			}
+			d := Delivery{Topic: topic, ConsumerGroup: group, Handler: name}
+			msgCtx := contextWithDelivery(propagator.Extract(ctx, carrier), d)
+
+			report := &failureReport{}
+			err := retryWithBackoff(msgCtx, msg, report.track(handler), cfg.retry, log)
+			if err == nil {
+				metrics.recordDelivery(msgCtx, d, report.attempts, true, false)
+				msg.Ack()
+				continue
+			}
+
+			if report.attempts > 0 && ctx.Err() == nil {
+				dlqErr := deadLetter(msgCtx, dlq, log, topic, name, msg, report)
+				metrics.recordDelivery(msgCtx, d, report.attempts, false, dlqErr == nil)
+				if dlqErr != nil {
+					err = errors.Join(err, dlqErr)
+					msg.Nack()
+				} else {
+					msg.Ack()
+				}
+			} else {
+				msg.Nack() // shutting down mid-retry: leave the message for the next consumer
+			}
+			select {
+			case errCh <- err:
+			default:
+				log.ErrorContext(msgCtx, "events: error channel full, dropping error",
+					"error", err, "topic", topic)
+			}
+		}
+	}()
+
+	return errCh
+}
+
+// deadLetter publishes msg to topic's dead-letter topic with failure details.
+func deadLetter(
+	ctx context.Context,
+	pub message.Publisher,
+	log logger.Logger,
+	topic, handler string,
+	msg *message.Message,
+	report *failureReport,
+) error {
+	dl := newDeadLetterMessage(msg, topic, handler, report)
+	if err := pub.Publish(DeadLetterTopic(topic), dl); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: dead-letter message %s: %w", msg.UUID, err)
+	}
+	log.ErrorContext(ctx, "events: message dead-lettered",
+		"topic", topic,
+		"message_uuid", msg.UUID,
+		"dead_letter_uuid", dl.UUID,
+		"attempts", report.attempts,
+		"handler", handler,
+		"error", report.lastErr,
+	)
+	return nil
+}
@@ -0,0 +1,307 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+const (
+	// MetaContentType is the content type of a message's payload. Messages
+	// without it carry a bare JSON event.
+	MetaContentType = "content_type"
+	// MetaEventTime is when a typed event was created (RFC 3339), used as the
+	// CloudEvents time attribute.
+	MetaEventTime = "event_time"
+
+	// CloudEventsContentType marks a payload in CloudEvents structured mode.
+	CloudEventsContentType = "application/cloudevents+json"
+	// CloudEventsSpecVersion is the CloudEvents version this package produces.
+	CloudEventsSpecVersion = "1.0"
+)
+
+// ErrInvalidCloudEvent is returned for a payload marked as a CloudEvent that
+// is malformed or lacks a required attribute.
+var ErrInvalidCloudEvent = errors.New("events: invalid cloudevent")
+
+// CloudEvent is a CloudEvents 1.0 event in structured JSON mode.
+//
+// Data holds a JSON payload; other payloads are carried in DataBase64.
+// Extensions hold the remaining attributes: traceparent and tracestate (the
+// distributed tracing extension) and partitionkey (the partitioning extension).
+type CloudEvent struct {
+	SpecVersion     string
+	ID              string
+	Source          string
+	Type            string
+	Subject         string
+	Time            time.Time
+	DataContentType string
+	DataSchema      string
+	Data            json.RawMessage
+	DataBase64      []byte
+	Extensions      map[string]string
+}
+
+// cloudEventAttributes maps message metadata to CloudEvents extension attributes.
+var cloudEventAttributes = map[string]string{
+	"traceparent":    "traceparent",
+	"tracestate":     "tracestate",
+	MetaPartitionKey: "partitionkey",
+}
+
+// CloudEventsConfig configures the envelope EventBus writes when
+// cfg.EventCloudEvents is enabled.
+type CloudEventsConfig struct {
+	// Source identifies the publishing service (CloudEvents source), e.g. "/hastyconnect".
+	Source string
+	// DataSchemaBase, if set, produces dataschema <base>/<type>/v<event_version>.
+	DataSchemaBase string
+}
+
+// ToCloudEvent returns msg, published to topic, as a structured-mode
+// CloudEvent message. The envelope's type is the topic, its id the event_id,
+// its subject the partition key (the aggregate ID of typed events) and its
+// time the event_time. Metadata is kept, so partitioning, deduplication and
+// tracing work as for bare messages. A message that already carries an
+// envelope is returned unchanged.
+func ToCloudEvent(topic string, msg *message.Message, cfg CloudEventsConfig) (*message.Message, error) {
+	if IsCloudEvent(msg) {
+		return msg, nil
+	}
+
+	ce := CloudEvent{
+		SpecVersion: CloudEventsSpecVersion,
+		ID:          eventIDOf(msg),
+		Source:      cfg.Source,
+		Type:        topic,
+		Subject:     msg.Metadata.Get(MetaPartitionKey),
+		Time:        time.Now().UTC(),
+		Extensions:  map[string]string{},
+	}
+	if raw := msg.Metadata.Get(MetaEventTime); raw != "" {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("events: parse %s of %s: %w", MetaEventTime, msg.UUID, err)
+		}
+		ce.Time = t
+	}
+	if version := msg.Metadata.Get(MetaEventVersion); cfg.DataSchemaBase != "" && version != "" {
+		ce.DataSchema = strings.TrimSuffix(cfg.DataSchemaBase, "/") + "/" + topic + "/v" + version
+	}
+	if json.Valid(msg.Payload) {
+		ce.DataContentType = "application/json"
+		ce.Data = json.RawMessage(msg.Payload)
+	} else {
+		ce.DataBase64 = msg.Payload
+	}
+	for key, attr := range cloudEventAttributes {
+		if v := msg.Metadata.Get(key); v != "" {
+			ce.Extensions[attr] = v
+		}
+	}
+
+	payload, err := json.Marshal(ce)
+	if err != nil {
+		return nil, fmt.Errorf("events: marshal cloudevent %s: %w", msg.UUID, err)
+	}
+	out := message.NewMessage(msg.UUID, payload)
+	for k, v := range msg.Metadata {
+		out.Metadata.Set(k, v)
+	}
+	out.Metadata.Set(MetaContentType, CloudEventsContentType)
+	return out, nil
+}
+
+// IsCloudEvent reports whether msg's payload is a structured-mode CloudEvent.
+func IsCloudEvent(msg *message.Message) bool {
+	return msg.Metadata.Get(MetaContentType) == CloudEventsContentType
+}
+
+// ParseCloudEvent decodes a structured-mode CloudEvent and checks its
+// required attributes. Returns ErrInvalidCloudEvent otherwise.
+func ParseCloudEvent(payload []byte) (CloudEvent, error) {
+	var ce CloudEvent
+	if err := json.Unmarshal(payload, &ce); err != nil {
+		return CloudEvent{}, fmt.Errorf("%w: %w", ErrInvalidCloudEvent, err)
+	}
+	for attr, v := range map[string]string{
+		"specversion": ce.SpecVersion,
+		"id":          ce.ID,
+		"source":      ce.Source,
+		"type":        ce.Type,
+	} {
+		if v == "" {
+			return CloudEvent{}, fmt.Errorf("%w: missing %s", ErrInvalidCloudEvent, attr)
+		}
+	}
+	if ce.SpecVersion != CloudEventsSpecVersion {
+		return CloudEvent{}, fmt.Errorf("%w: unsupported specversion %q", ErrInvalidCloudEvent, ce.SpecVersion)
+	}
+	return ce, nil
+}
+
+// FromCloudEvent returns a copy of msg with its CloudEvent envelope removed:
+// the payload is the event data, and event_id, event_time and partition_key
+// are filled from the envelope where msg's metadata lacks them. Messages
+// without an envelope are returned unchanged.
+func FromCloudEvent(msg *message.Message) (*message.Message, error) {
+	if !IsCloudEvent(msg) {
+		return msg, nil
+	}
+	ce, err := ParseCloudEvent(msg.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := []byte(ce.Data)
+	if ce.Data == nil {
+		payload = ce.DataBase64
+	}
+	out := message.NewMessage(msg.UUID, payload)
+	for k, v := range msg.Metadata {
+		out.Metadata.Set(k, v)
+	}
+	delete(out.Metadata, MetaContentType)
+	setIfMissing := func(key, v string) {
+		if out.Metadata.Get(key) == "" && v != "" {
+			out.Metadata.Set(key, v)
+		}
+	}
+	setIfMissing(MetaEventID, ce.ID)
+	setIfMissing(MetaPartitionKey, ce.Subject)
+	if !ce.Time.IsZero() {
+		setIfMissing(MetaEventTime, ce.Time.Format(time.RFC3339Nano))
+	}
+	for key, attr := range cloudEventAttributes {
+		setIfMissing(key, ce.Extensions[attr])
+	}
+	return out, nil
+}
+
+// withCloudEvents unwraps CloudEvent envelopes before handler runs, so
+// handlers see the same payload whether or not the publisher used one. A
+// malformed envelope fails permanently.
+func withCloudEvents(handler Handler) Handler {
+	return func(ctx context.Context, msg *message.Message) error {
+		unwrapped, err := FromCloudEvent(msg)
+		if err != nil {
+			return Permanent(err)
+		}
+		return handler(ctx, unwrapped)
+	}
+}
+
+// cloudEventsPublisher wraps every published message in a CloudEvent envelope.
+type cloudEventsPublisher struct {
+	next message.Publisher
+	cfg  CloudEventsConfig
+}
+
+func (p cloudEventsPublisher) Publish(topic string, msgs ...*message.Message) error {
+	wrapped := make([]*message.Message, len(msgs))
+	for i, msg := range msgs {
+		ce, err := ToCloudEvent(topic, msg, p.cfg)
+		if err != nil {
+			return err
+		}
+		wrapped[i] = ce
+	}
+	return p.next.Publish(topic, wrapped...)
+}
+
+func (p cloudEventsPublisher) Close() error { return p.next.Close() }
+
+// MarshalJSON encodes ce in CloudEvents JSON format, with extensions as
+// top-level attributes.
+func (ce CloudEvent) MarshalJSON() ([]byte, error) {
+	attrs := make(map[string]any, len(ce.Extensions)+10)
+	for k, v := range ce.Extensions {
+		attrs[k] = v
+	}
+	attrs["specversion"] = ce.SpecVersion
+	attrs["id"] = ce.ID
+	attrs["source"] = ce.Source
+	attrs["type"] = ce.Type
+	optional := map[string]string{
+		"subject":         ce.Subject,
+		"datacontenttype": ce.DataContentType,
+		"dataschema":      ce.DataSchema,
+	}
+	for k, v := range optional {
+		if v != "" {
+			attrs[k] = v
+		}
+	}
+	if !ce.Time.IsZero() {
+		attrs["time"] = ce.Time.Format(time.RFC3339Nano)
+	}
+	if ce.Data != nil {
+		attrs["data"] = ce.Data
+	} else if ce.DataBase64 != nil {
+		attrs["data_base64"] = ce.DataBase64
+	}
+	return json.Marshal(attrs)
+}
+
+// UnmarshalJSON decodes ce from CloudEvents JSON format. Attributes other
+// than the core ones and data become Extensions.
+func (ce *CloudEvent) UnmarshalJSON(b []byte) error {
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(b, &attrs); err != nil {
+		return err
+	}
+	*ce = CloudEvent{}
+
+	for name, raw := range attrs {
+		switch name {
+		case "data":
+			ce.Data = bytes.Clone(raw)
+			continue
+		case "data_base64":
+			if err := json.Unmarshal(raw, &ce.DataBase64); err != nil {
+				return fmt.Errorf("data_base64: %w", err)
+			}
+			continue
+		}
+
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw) // extensions may be numbers or booleans
+		}
+		switch name {
+		case "specversion":
+			ce.SpecVersion = s
+		case "id":
+			ce.ID = s
+		case "source":
+			ce.Source = s
+		case "type":
+			ce.Type = s
+		case "subject":
+			ce.Subject = s
+		case "datacontenttype":
+			ce.DataContentType = s
+		case "dataschema":
+			ce.DataSchema = s
+		case "time":
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("time: %w", err)
+			}
+			ce.Time = t
+		default:
+			if ce.Extensions == nil {
+				ce.Extensions = map[string]string{}
+			}
+			ce.Extensions[name] = s
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+)
+
+// TestCloudEvent_RoundTrip verifies a typed message survives wrapping and
+// unwrapping, and that the envelope carries the standard attributes.
+func TestCloudEvent_RoundTrip(t *testing.T) {
+	evt := testAggregateEvent{ItemID: uuid.New()}
+	topic, msg, err := NewTypedMessage(context.Background(), evt)
+	if err != nil {
+		t.Fatalf("NewTypedMessage: %v", err)
+	}
+
+	wrapped, err := ToCloudEvent(topic, msg, CloudEventsConfig{Source: "/test", DataSchemaBase: "https://schemas.example.com/"})
+	if err != nil {
+		t.Fatalf("ToCloudEvent: %v", err)
+	}
+	if !IsCloudEvent(wrapped) {
+		t.Fatalf("content_type: got %q", wrapped.Metadata.Get(MetaContentType))
+	}
+
+	var attrs map[string]any
+	if err := json.Unmarshal(wrapped.Payload, &attrs); err != nil {
+		t.Fatalf("unmarshal envelope: %v", err)
+	}
+	for attr, want := range map[string]string{
+		"specversion":  "1.0",
+		"id":           msg.Metadata.Get(MetaEventID),
+		"source":       "/test",
+		"type":         "test.aggregate",
+		"subject":      evt.ItemID.String(),
+		"dataschema":   "https://schemas.example.com/test.aggregate/v1",
+		"partitionkey": evt.ItemID.String(),
+		"time":         msg.Metadata.Get(MetaEventTime),
+	} {
+		if got := attrs[attr]; got != want {
+			t.Errorf("%s: got %v, want %q", attr, got, want)
+		}
+	}
+
+	unwrapped, err := FromCloudEvent(wrapped)
+	if err != nil {
+		t.Fatalf("FromCloudEvent: %v", err)
+	}
+	if IsCloudEvent(unwrapped) {
+		t.Error("unwrapped message still marked as a cloudevent")
+	}
+	decoded, err := DecodeTyped[testAggregateEvent](unwrapped)
+	if err != nil {
+		t.Fatalf("DecodeTyped: %v", err)
+	}
+	if decoded != evt {
+		t.Errorf("decoded: got %+v, want %+v", decoded, evt)
+	}
+
+	again, err := ToCloudEvent(topic, wrapped, CloudEventsConfig{Source: "/test"})
+	if err != nil || again != wrapped {
+		t.Errorf("wrapping twice: got new message %v (err %v), want it unchanged", again != wrapped, err)
+	}
+}
+
+// TestFromCloudEvent_ExternalEnvelope verifies envelopes from other producers,
+// with binary data and no bus metadata, are unwrapped with metadata filled in.
+func TestFromCloudEvent_ExternalEnvelope(t *testing.T) {
+	msg := message.NewMessage("uuid-1", []byte(`{
+		"specversion": "1.0", "id": "evt-1", "source": "urn:other", "type": "other.thing",
+		"subject": "key-1", "time": "2026-01-02T03:04:05Z", "data_base64": "AAEC", "retries": 3
+	}`))
+	msg.Metadata.Set(MetaContentType, CloudEventsContentType)
+
+	out, err := FromCloudEvent(msg)
+	if err != nil {
+		t.Fatalf("FromCloudEvent: %v", err)
+	}
+	if string(out.Payload) != "\x00\x01\x02" {
+		t.Errorf("payload: got %q", out.Payload)
+	}
+	if got := out.Metadata.Get(MetaEventID); got != "evt-1" {
+		t.Errorf("event_id: got %q", got)
+	}
+	if got := PartitionKeyOf(out); got != "key-1" {
+		t.Errorf("partition key: got %q", got)
+	}
+	if got := out.Metadata.Get(MetaEventTime); got != time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC).Format(time.RFC3339Nano) {
+		t.Errorf("event_time: got %q", got)
+	}
+}
+
+// TestWithCloudEvents_Invalid verifies a malformed envelope fails permanently
+// and a bare message passes through untouched.
+func TestWithCloudEvents_Invalid(t *testing.T) {
+	var got *message.Message
+	handler := withCloudEvents(func(_ context.Context, msg *message.Message) error {
+		got = msg
+		return nil
+	})
+
+	bad := message.NewMessage("uuid-1", []byte(`{"specversion":"1.0","id":"x"}`))
+	bad.Metadata.Set(MetaContentType, CloudEventsContentType)
+	if err := handler(context.Background(), bad); !IsPermanent(err) || !errors.Is(err, ErrInvalidCloudEvent) {
+		t.Errorf("malformed envelope: got %v, want permanent ErrInvalidCloudEvent", err)
+	}
+
+	bare := message.NewMessage("uuid-2", []byte(`{}`))
+	if err := handler(context.Background(), bare); err != nil || got != bare {
+		t.Errorf("bare message: err %v, passed through unchanged: %v", err, got == bare)
+	}
+}
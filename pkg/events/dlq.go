@@ -0,0 +1,303 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	watermillsql "github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// DeadLetterSuffix is appended to a topic name to form its dead-letter topic.
+const DeadLetterSuffix = ".dlq"
+
+// Metadata keys attached to dead-lettered messages. The original message
+// metadata (event_id, event_version, trace headers) is preserved alongside them.
+const (
+	MetaDLQTopic         = "dlq_topic"
+	MetaDLQError         = "dlq_error"
+	MetaDLQAttempts      = "dlq_attempts"
+	MetaDLQHandler       = "dlq_handler"
+	MetaDLQFirstFailedAt = "dlq_first_failed_at"
+	MetaDLQLastFailedAt  = "dlq_last_failed_at"
+)
+
+// ErrDeadLetterNotFound is returned when a dead-letter message does not exist.
+var ErrDeadLetterNotFound = errors.New("events: dead letter not found")
+
+// ErrInvalidTopic is returned for topic names that cannot back a Watermill table.
+var ErrInvalidTopic = errors.New("events: invalid topic name")
+
+// topicNamePattern mirrors watermill-sql's topic validation. Topic names are
+// interpolated into table names, so they must be checked before use in SQL.
+var topicNamePattern = regexp.MustCompile(`^[A-Za-z0-9\-\$\:\.\_]+$`)
+
+// DeadLetterTopic returns the dead-letter topic for topic, e.g. "item.created.dlq".
+func DeadLetterTopic(topic string) string {
+	return topic + DeadLetterSuffix
+}
+
+// DeadLetter is a message that exhausted its retries, together with the
+// failure details recorded when it was dead-lettered.
+type DeadLetter struct {
+	UUID           string            `json:"uuid"`
+	Topic          string            `json:"topic"` // original topic the message was consumed from
+	Payload        json.RawMessage   `json:"payload"`
+	Metadata       map[string]string `json:"metadata"`
+	Error          string            `json:"error"`
+	Attempts       int               `json:"attempts"`
+	Handler        string            `json:"handler"`
+	FirstFailedAt  time.Time         `json:"first_failed_at"`
+	LastFailedAt   time.Time         `json:"last_failed_at"`
+	DeadLetteredAt time.Time         `json:"dead_lettered_at"`
+}
+
+// failureReport records handler failures across the retry attempts of a single delivery.
+type failureReport struct {
+	attempts      int
+	lastErr       error
+	firstFailedAt time.Time
+	lastFailedAt  time.Time
+}
+
+// track wraps handler so every failed attempt is recorded on r.
+func (r *failureReport) track(handler func(context.Context, *message.Message) error) func(context.Context, *message.Message) error {
+	return func(ctx context.Context, msg *message.Message) error {
+		err := handler(ctx, msg)
+		if err != nil {
+			now := time.Now().UTC()
+			if r.attempts == 0 {
+				r.firstFailedAt = now
+			}
+			r.attempts++
+			r.lastErr = err
+			r.lastFailedAt = now
+		}
+		return err
+	}
+}
+
+// handlerName returns the fully-qualified function name of handler for DLQ
+// diagnostics, e.g. "main.handleItemCreated.func1".
+func handlerName(handler any) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "unknown"
+}
+
+// newDeadLetterMessage copies msg into a new message for the dead-letter topic,
+// keeping the original payload and metadata and adding failure details.
+func newDeadLetterMessage(msg *message.Message, topic, handler string, report *failureReport) *message.Message {
+	dl := message.NewMessage(watermill.NewUUID(), msg.Payload)
+	for k, v := range msg.Metadata {
+		dl.Metadata.Set(k, v)
+	}
+	errText := ""
+	if report.lastErr != nil {
+		errText = report.lastErr.Error()
+	}
+	dl.Metadata.Set(MetaDLQTopic, topic)
+	dl.Metadata.Set(MetaDLQError, errText)
+	dl.Metadata.Set(MetaDLQAttempts, strconv.Itoa(report.attempts))
+	dl.Metadata.Set(MetaDLQHandler, handler)
+	dl.Metadata.Set(MetaDLQFirstFailedAt, report.firstFailedAt.Format(time.RFC3339Nano))
+	dl.Metadata.Set(MetaDLQLastFailedAt, report.lastFailedAt.Format(time.RFC3339Nano))
+	return dl
+}
+
+// deadLetterFromRow maps a stored dead-letter message back to a DeadLetter.
+// The dlq_* keys are lifted into typed fields; the rest stay in Metadata.
+func deadLetterFromRow(uuid string, payload []byte, metadata map[string]string, createdAt time.Time) DeadLetter {
+	dl := DeadLetter{
+		UUID:           uuid,
+		Payload:        payload,
+		Metadata:       make(map[string]string, len(metadata)),
+		Topic:          metadata[MetaDLQTopic],
+		Error:          metadata[MetaDLQError],
+		Handler:        metadata[MetaDLQHandler],
+		DeadLetteredAt: createdAt,
+	}
+	dl.Attempts, _ = strconv.Atoi(metadata[MetaDLQAttempts])
+	dl.FirstFailedAt, _ = time.Parse(time.RFC3339Nano, metadata[MetaDLQFirstFailedAt])
+	dl.LastFailedAt, _ = time.Parse(time.RFC3339Nano, metadata[MetaDLQLastFailedAt])
+	for k, v := range metadata {
+		if !strings.HasPrefix(k, "dlq_") {
+			dl.Metadata[k] = v
+		}
+	}
+	return dl
+}
+
+// deadLetterTable returns the quoted Watermill table backing topic's DLQ.
+func deadLetterTable(topic string) (string, error) {
+	if !topicNamePattern.MatchString(topic) {
+		return "", fmt.Errorf("%w %q", ErrInvalidTopic, topic)
+	}
+	return watermillsql.DefaultPostgreSQLSchema{}.MessagesTable(DeadLetterTopic(topic)), nil
+}
+
+// tableExists reports whether the quoted Watermill table has been created.
+// Watermill creates a topic's tables lazily, e.g. the DLQ table on the first
+// dead-lettered message.
+func (q *EventBus) tableExists(ctx context.Context, table string) (bool, error) {
+	var exists bool
+	if err := q.db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists); err != nil {
+		return false, fmt.Errorf("events: check table %s: %w", table, err)
+	}
+	return exists, nil
+}
+
+// ListDeadLetters returns up to limit dead-lettered messages for topic, oldest first.
+// topic is the original topic (e.g. "item.created"), not the ".dlq" topic.
+func (q *EventBus) ListDeadLetters(ctx context.Context, topic string, limit int) ([]DeadLetter, error) {
+	table, err := deadLetterTable(topic)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := q.tableExists(ctx, table); err != nil || !ok {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx,
+		`SELECT uuid, payload, metadata, created_at FROM `+table+` ORDER BY "offset" LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("events: list dead letters for %s: %w", topic, err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []DeadLetter
+	for rows.Next() {
+		dl, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("events: list dead letters for %s: %w", topic, err)
+		}
+		out = append(out, dl)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("events: list dead letters for %s: %w", topic, err)
+	}
+	return out, nil
+}
+
+// GetDeadLetter returns a single dead-lettered message by its UUID.
+// Returns ErrDeadLetterNotFound if it does not exist.
+func (q *EventBus) GetDeadLetter(ctx context.Context, topic, uuid string) (*DeadLetter, error) {
+	table, err := deadLetterTable(topic)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := q.tableExists(ctx, table); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrDeadLetterNotFound
+	}
+
+	row := q.db.QueryRowContext(ctx,
+		`SELECT uuid, payload, metadata, created_at FROM `+table+` WHERE uuid = $1`, uuid)
+	dl, err := scanDeadLetter(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("events: get dead letter %s: %w", uuid, err)
+	}
+	return &dl, nil
+}
+
+// RequeueDeadLetter republishes a dead-lettered message to its original topic
+// with its original metadata and removes it from the DLQ. Both steps run in one
+// transaction, so the message is never lost or duplicated by a failed requeue.
+// Returns ErrDeadLetterNotFound if it does not exist.
+func (q *EventBus) RequeueDeadLetter(ctx context.Context, topic, uuid string) error {
+	dl, err := q.GetDeadLetter(ctx, topic, uuid)
+	if err != nil {
+		return err
+	}
+	table, _ := deadLetterTable(topic) // validated by GetDeadLetter
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("events: begin requeue tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE uuid = $1`, uuid)
+	if err != nil {
+		return fmt.Errorf("events: delete dead letter %s: %w", uuid, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDeadLetterNotFound // requeued or purged concurrently
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), []byte(dl.Payload))
+	for k, v := range dl.Metadata {
+		msg.Metadata.Set(k, v)
+	}
+	pub, err := q.NewTxPublisher(tx)
+	if err != nil {
+		return err
+	}
+	if err := pub.Publish(topic, msg); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: requeue dead letter %s to %s: %w", uuid, topic, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("events: commit requeue tx: %w", err)
+	}
+	q.log.InfoContext(ctx, "events: dead letter requeued", "topic", topic, "uuid", uuid)
+	return nil
+}
+
+// PurgeDeadLetters permanently deletes all dead-lettered messages for topic
+// and returns how many were removed.
+func (q *EventBus) PurgeDeadLetters(ctx context.Context, topic string) (int64, error) {
+	table, err := deadLetterTable(topic)
+	if err != nil {
+		return 0, err
+	}
+	if ok, err := q.tableExists(ctx, table); err != nil || !ok {
+		return 0, err
+	}
+
+	res, err := q.db.ExecContext(ctx, `DELETE FROM `+table)
+	if err != nil {
+		return 0, fmt.Errorf("events: purge dead letters for %s: %w", topic, err)
+	}
+	n, _ := res.RowsAffected()
+	q.log.InfoContext(ctx, "events: dead letters purged", "topic", topic, "count", n)
+	return n, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDeadLetter(row rowScanner) (DeadLetter, error) {
+	var (
+		uuid      string
+		payload   []byte
+		rawMeta   []byte
+		createdAt time.Time
+	)
+	if err := row.Scan(&uuid, &payload, &rawMeta, &createdAt); err != nil {
+		return DeadLetter{}, err
+	}
+	metadata := map[string]string{}
+	if len(rawMeta) > 0 {
+		if err := json.Unmarshal(rawMeta, &metadata); err != nil {
+			return DeadLetter{}, fmt.Errorf("unmarshal metadata: %w", err)
+		}
+	}
+	return deadLetterFromRow(uuid, payload, metadata, createdAt), nil
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// TestFailureReport_Track verifies attempts, last error and failure times are recorded.
+func TestFailureReport_Track(t *testing.T) {
+	report := &failureReport{}
+	calls := 0
+	handler := report.track(func(_ context.Context, _ *message.Message) error {
+		calls++
+		if calls == 3 {
+			return nil
+		}
+		return errors.New("boom " + string(rune('0'+calls)))
+	})
+
+	msg := message.NewMessage("id", nil)
+	for i := 0; i < 3; i++ {
+		_ = handler(context.Background(), msg)
+	}
+
+	if report.attempts != 2 {
+		t.Errorf("attempts: got %d, want 2", report.attempts)
+	}
+	if report.lastErr == nil || report.lastErr.Error() != "boom 2" {
+		t.Errorf("lastErr: got %v, want boom 2", report.lastErr)
+	}
+	if report.firstFailedAt.IsZero() || report.lastFailedAt.Before(report.firstFailedAt) {
+		t.Errorf("unexpected failure times: first=%v last=%v", report.firstFailedAt, report.lastFailedAt)
+	}
+}
+
+// TestDeadLetterMessage_RoundTrip verifies failure details survive the trip
+// through message metadata and the original metadata is preserved separately.
+func TestDeadLetterMessage_RoundTrip(t *testing.T) {
+	first := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	report := &failureReport{
+		attempts:      3,
+		lastErr:       errors.New("redis down"),
+		firstFailedAt: first,
+		lastFailedAt:  first.Add(3 * time.Second),
+	}
+	orig := message.NewMessage("orig-uuid", []byte(`{"item_id":"1"}`))
+	orig.Metadata.Set("event_id", "evt-1")
+
+	dl := newDeadLetterMessage(orig, "item.created", "main.handleItemCreated.func1", report)
+	if dl.UUID == orig.UUID {
+		t.Error("dead letter must get a fresh UUID")
+	}
+
+	got := deadLetterFromRow(dl.UUID, dl.Payload, dl.Metadata, time.Now())
+	if got.Topic != "item.created" || got.Error != "redis down" || got.Attempts != 3 ||
+		got.Handler != "main.handleItemCreated.func1" {
+		t.Errorf("unexpected dead letter: %+v", got)
+	}
+	if !got.FirstFailedAt.Equal(report.firstFailedAt) || !got.LastFailedAt.Equal(report.lastFailedAt) {
+		t.Errorf("failure times: got %v..%v", got.FirstFailedAt, got.LastFailedAt)
+	}
+	if string(got.Payload) != string(orig.Payload) {
+		t.Errorf("payload: got %s, want %s", got.Payload, orig.Payload)
+	}
+	if got.Metadata["event_id"] != "evt-1" {
+		t.Errorf("original metadata lost: %+v", got.Metadata)
+	}
+	for k := range got.Metadata {
+		if strings.HasPrefix(k, "dlq_") {
+			t.Errorf("dlq key %q leaked into original metadata", k)
+		}
+	}
+}
+
+// TestDeadLetterTable_RejectsUnsafeTopic verifies topic names are validated
+// before being interpolated into SQL.
+func TestDeadLetterTable_RejectsUnsafeTopic(t *testing.T) {
+	if _, err := deadLetterTable(`item"; DROP TABLE x; --`); err == nil {
+		t.Fatal("expected error for unsafe topic name")
+	}
+	table, err := deadLetterTable("item.created")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table != `"watermill_item.created.dlq"` {
+		t.Errorf("table: got %s", table)
+	}
+}
+
+// TestHandlerName verifies handler names resolve to the function symbol.
+func TestHandlerName(t *testing.T) {
+	name := handlerName(TestHandlerName)
+	if !strings.HasSuffix(name, "events.TestHandlerName") {
+		t.Errorf("unexpected handler name %q", name)
+	}
+}
@@ -0,0 +1,261 @@
+// Package eventsadmin exposes consumer group offsets, scheduled messages and
+// dead letters over HTTP for operators: listing each group's position and lag,
+// rewinding or fast-forwarding a group to an offset or a point in time,
+// listing or cancelling messages scheduled with PublishAt, and listing,
+// inspecting, requeueing or purging a topic's dead letters. Replaying a topic into one handler is
+// only available from the worker CLI (`worker events replay`), since the
+// handlers live in the worker process.
+package eventsadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ghuser/ghproject/pkg/events"
+	"github.com/ghuser/ghproject/pkg/httpx"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// Offsets is the subset of *events.EventBus the admin endpoints use.
+type Offsets interface {
+	ConsumerGroups(ctx context.Context, topic string) ([]events.ConsumerGroupOffset, error)
+	OffsetAt(ctx context.Context, topic string, t time.Time) (int64, error)
+	ResetOffset(ctx context.Context, topic, consumerGroup string, offset int64) error
+}
+
+// Scheduled is the subset of *events.EventBus the scheduled message endpoints
+// use.
+type Scheduled interface {
+	ListScheduled(ctx context.Context, topic string, limit int) ([]events.ScheduledMessage, error)
+	CancelScheduled(ctx context.Context, uuid string) error
+}
+
+// DeadLetters is the subset of *events.EventBus the dead-letter endpoints use.
+type DeadLetters interface {
+	ListDeadLetters(ctx context.Context, topic string, limit int) ([]events.DeadLetter, error)
+	GetDeadLetter(ctx context.Context, topic, uuid string) (*events.DeadLetter, error)
+	RequeueDeadLetter(ctx context.Context, topic, uuid string) error
+	PurgeDeadLetters(ctx context.Context, topic string) (int64, error)
+}
+
+// Bus is everything the admin endpoints use; *events.EventBus satisfies it.
+type Bus interface {
+	Offsets
+	Scheduled
+	DeadLetters
+}
+
+const (
+	// defaultListLimit is the page size of list endpoints without a limit
+	// parameter.
+	defaultListLimit = 100
+
+	// maxListLimit caps the limit parameter of list endpoints.
+	maxListLimit = 1000
+)
+
+// ResetRequest is the request body for POST .../reset. Exactly one of Offset
+// and Time must be set. Offset 0 rewinds to the start of the topic; Time
+// positions the group after the last message published before it.
+type ResetRequest struct {
+	Offset *int64     `json:"offset,omitempty"`
+	Time   *time.Time `json:"time,omitempty"`
+}
+
+// ResetResponse reports the offset a consumer group was reset to.
+type ResetResponse struct {
+	Topic         string `json:"topic"`
+	ConsumerGroup string `json:"consumer_group"`
+	Offset        int64  `json:"offset"`
+}
+
+// Routes registers the event admin endpoints on r:
+//
+//	GET  /events/consumer-groups?topic=<topic>
+//	POST /events/topics/{topic}/consumer-groups/{group}/reset
+//	GET  /events/scheduled?topic=<topic>&limit=<n>
+//	DELETE /events/scheduled/{uuid}
+//	GET  /events/topics/{topic}/dlq?limit=<n>
+//	GET  /events/topics/{topic}/dlq/{uuid}
+//	POST /events/topics/{topic}/dlq/{uuid}/requeue
+//	DELETE /events/topics/{topic}/dlq
+//
+// Mount them behind operator authentication; they can make every subscriber
+// reprocess its history.
+func Routes(r chi.Router, bus Bus, log logger.Logger) {
+	r.Get("/events/consumer-groups", listConsumerGroups(bus, log))
+	r.Post("/events/topics/{topic}/consumer-groups/{group}/reset", resetConsumerGroup(bus, log))
+	r.Get("/events/scheduled", listScheduled(bus, log))
+	r.Delete("/events/scheduled/{uuid}", cancelScheduled(bus, log))
+	r.Get("/events/topics/{topic}/dlq", listDeadLetters(bus, log))
+	r.Get("/events/topics/{topic}/dlq/{uuid}", getDeadLetter(bus, log))
+	r.Post("/events/topics/{topic}/dlq/{uuid}/requeue", requeueDeadLetter(bus, log))
+	r.Delete("/events/topics/{topic}/dlq", purgeDeadLetters(bus, log))
+}
+
+func listConsumerGroups(offsets Offsets, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		groups, err := offsets.ConsumerGroups(r.Context(), r.URL.Query().Get("topic"))
+		if err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		if groups == nil {
+			groups = []events.ConsumerGroupOffset{}
+		}
+		httpx.JSON(w, http.StatusOK, map[string]any{"consumer_groups": groups})
+	}
+}
+
+func resetConsumerGroup(offsets Offsets, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		topic, group := chi.URLParam(r, "topic"), chi.URLParam(r, "group")
+
+		var req ResetRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpx.JSONError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+		if (req.Offset == nil) == (req.Time == nil) {
+			httpx.JSONError(w, http.StatusUnprocessableEntity, "exactly one of offset and time is required")
+			return
+		}
+
+		var offset int64
+		switch {
+		case req.Offset != nil:
+			if *req.Offset < 0 {
+				httpx.JSONError(w, http.StatusUnprocessableEntity, "offset must not be negative")
+				return
+			}
+			offset = *req.Offset
+		default:
+			var err error
+			if offset, err = offsets.OffsetAt(r.Context(), topic, *req.Time); err != nil {
+				writeError(w, r, log, err)
+				return
+			}
+		}
+
+		if err := offsets.ResetOffset(r.Context(), topic, group, offset); err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		httpx.JSON(w, http.StatusOK, ResetResponse{Topic: topic, ConsumerGroup: group, Offset: offset})
+	}
+}
+
+func listScheduled(scheduled Scheduled, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := parseLimit(w, r)
+		if !ok {
+			return
+		}
+		msgs, err := scheduled.ListScheduled(r.Context(), r.URL.Query().Get("topic"), limit)
+		if err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		if msgs == nil {
+			msgs = []events.ScheduledMessage{}
+		}
+		httpx.JSON(w, http.StatusOK, map[string]any{"scheduled": msgs})
+	}
+}
+
+func cancelScheduled(scheduled Scheduled, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := scheduled.CancelScheduled(r.Context(), chi.URLParam(r, "uuid")); err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func listDeadLetters(dlq DeadLetters, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := parseLimit(w, r)
+		if !ok {
+			return
+		}
+		dls, err := dlq.ListDeadLetters(r.Context(), chi.URLParam(r, "topic"), limit)
+		if err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		if dls == nil {
+			dls = []events.DeadLetter{}
+		}
+		httpx.JSON(w, http.StatusOK, map[string]any{"dead_letters": dls})
+	}
+}
+
+func getDeadLetter(dlq DeadLetters, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		dl, err := dlq.GetDeadLetter(r.Context(), chi.URLParam(r, "topic"), chi.URLParam(r, "uuid"))
+		if err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		httpx.JSON(w, http.StatusOK, dl)
+	}
+}
+
+func requeueDeadLetter(dlq DeadLetters, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := dlq.RequeueDeadLetter(r.Context(), chi.URLParam(r, "topic"), chi.URLParam(r, "uuid")); err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func purgeDeadLetters(dlq DeadLetters, log logger.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		n, err := dlq.PurgeDeadLetters(r.Context(), chi.URLParam(r, "topic"))
+		if err != nil {
+			writeError(w, r, log, err)
+			return
+		}
+		httpx.JSON(w, http.StatusOK, map[string]any{"purged": n})
+	}
+}
+
+// parseLimit returns the limit query parameter, defaultListLimit if absent.
+// It writes a 400 and returns false if the parameter is invalid.
+func parseLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultListLimit, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxListLimit {
+		httpx.JSONError(w, http.StatusBadRequest, "limit must be between 1 and "+strconv.Itoa(maxListLimit))
+		return 0, false
+	}
+	return n, true
+}
+
+// writeError maps events errors to client errors and logs everything else.
+func writeError(w http.ResponseWriter, r *http.Request, log logger.Logger, err error) {
+	switch {
+	case errors.Is(err, events.ErrInvalidTopic):
+		httpx.JSONError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, events.ErrOffsetNotFound), errors.Is(err, events.ErrScheduledNotFound),
+		errors.Is(err, events.ErrDeadLetterNotFound):
+		httpx.JSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, events.ErrTransportUnsupported):
+		httpx.JSONError(w, http.StatusNotImplemented, err.Error())
+	default:
+		log.ErrorContext(r.Context(), "events admin request failed", "error", err)
+		httpx.JSONError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+}
@@ -0,0 +1,336 @@
+package eventsadmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/events"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// fakeOffsets records resets, cancellations, requeues and purges and serves
+// fixed consumer groups, scheduled messages and dead letters.
+type fakeOffsets struct {
+	groups    []events.ConsumerGroupOffset
+	offsetAt  int64
+	resetTo   int64
+	resetFor  string
+	resetErr  error
+	listTopic string
+
+	scheduled      []events.ScheduledMessage
+	scheduledLimit int
+	cancelled      string
+	cancelErr      error
+
+	deadLetters []events.DeadLetter
+	dlqLimit    int
+	requeued    string
+	purgedTopic string
+	dlqErr      error
+}
+
+func (f *fakeOffsets) ConsumerGroups(_ context.Context, topic string) ([]events.ConsumerGroupOffset, error) {
+	f.listTopic = topic
+	return f.groups, nil
+}
+
+func (f *fakeOffsets) OffsetAt(_ context.Context, _ string, _ time.Time) (int64, error) {
+	return f.offsetAt, nil
+}
+
+func (f *fakeOffsets) ResetOffset(_ context.Context, topic, group string, offset int64) error {
+	f.resetFor, f.resetTo = topic+"/"+group, offset
+	return f.resetErr
+}
+
+func (f *fakeOffsets) ListScheduled(_ context.Context, topic string, limit int) ([]events.ScheduledMessage, error) {
+	f.listTopic, f.scheduledLimit = topic, limit
+	return f.scheduled, nil
+}
+
+func (f *fakeOffsets) CancelScheduled(_ context.Context, uuid string) error {
+	f.cancelled = uuid
+	return f.cancelErr
+}
+
+func (f *fakeOffsets) ListDeadLetters(_ context.Context, topic string, limit int) ([]events.DeadLetter, error) {
+	f.listTopic, f.dlqLimit = topic, limit
+	return f.deadLetters, f.dlqErr
+}
+
+func (f *fakeOffsets) GetDeadLetter(_ context.Context, topic, uuid string) (*events.DeadLetter, error) {
+	f.listTopic = topic
+	for _, dl := range f.deadLetters {
+		if dl.UUID == uuid {
+			return &dl, nil
+		}
+	}
+	return nil, events.ErrDeadLetterNotFound
+}
+
+func (f *fakeOffsets) RequeueDeadLetter(_ context.Context, topic, uuid string) error {
+	f.requeued = topic + "/" + uuid
+	return f.dlqErr
+}
+
+func (f *fakeOffsets) PurgeDeadLetters(_ context.Context, topic string) (int64, error) {
+	f.purgedTopic = topic
+	return int64(len(f.deadLetters)), f.dlqErr
+}
+
+func newRouter(f *fakeOffsets) http.Handler {
+	r := chi.NewRouter()
+	Routes(r, f, logger.New(&config.Config{LogLevel: "error"}))
+	return r
+}
+
+func TestListConsumerGroups(t *testing.T) {
+	f := &fakeOffsets{groups: []events.ConsumerGroupOffset{
+		{Topic: "item.created", ConsumerGroup: "worker", Offset: 7, Lag: 2},
+	}}
+	w := httptest.NewRecorder()
+	newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/consumer-groups?topic=item.created", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if f.listTopic != "item.created" {
+		t.Errorf("topic = %q, want item.created", f.listTopic)
+	}
+	var body struct {
+		ConsumerGroups []events.ConsumerGroupOffset `json:"consumer_groups"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.ConsumerGroups) != 1 || body.ConsumerGroups[0].Lag != 2 {
+		t.Errorf("consumer_groups = %+v", body.ConsumerGroups)
+	}
+}
+
+func TestResetConsumerGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		resetErr   error
+		wantStatus int
+		wantOffset int64
+	}{
+		{"to offset", `{"offset": 42}`, nil, http.StatusOK, 42},
+		{"to time", `{"time": "2026-01-02T03:04:05Z"}`, nil, http.StatusOK, 9},
+		{"neither", `{}`, nil, http.StatusUnprocessableEntity, 0},
+		{"both", `{"offset": 1, "time": "2026-01-02T03:04:05Z"}`, nil, http.StatusUnprocessableEntity, 0},
+		{"negative", `{"offset": -1}`, nil, http.StatusUnprocessableEntity, 0},
+		{"invalid json", `{`, nil, http.StatusBadRequest, 0},
+		{"unknown offset", `{"offset": 5}`, fmt.Errorf("%w: 5", events.ErrOffsetNotFound), http.StatusNotFound, 5},
+		{"invalid topic", `{"offset": 0}`, fmt.Errorf("%w %q", events.ErrInvalidTopic, "x"), http.StatusBadRequest, 0},
+		{"redis transport", `{"offset": 1}`, events.ErrTransportUnsupported, http.StatusNotImplemented, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeOffsets{offsetAt: 9, resetErr: tt.resetErr}
+			req := httptest.NewRequest(http.MethodPost,
+				"/events/topics/item.created/consumer-groups/worker/reset", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			newRouter(f).ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if f.resetFor != "item.created/worker" || f.resetTo != tt.wantOffset {
+				t.Errorf("reset %s to %d, want item.created/worker to %d", f.resetFor, f.resetTo, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestListScheduled(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantLimit  int
+	}{
+		{"default limit", "?topic=item.created", http.StatusOK, 100},
+		{"explicit limit", "?topic=item.created&limit=5", http.StatusOK, 5},
+		{"zero limit", "?limit=0", http.StatusBadRequest, 0},
+		{"limit too large", "?limit=1001", http.StatusBadRequest, 0},
+		{"invalid limit", "?limit=ten", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeOffsets{scheduled: []events.ScheduledMessage{
+				{UUID: "m1", Topic: "item.created", Payload: []byte(`{}`)},
+			}}
+			w := httptest.NewRecorder()
+			newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/scheduled"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if f.listTopic != "item.created" || f.scheduledLimit != tt.wantLimit {
+				t.Errorf("listed topic %q limit %d, want item.created limit %d", f.listTopic, f.scheduledLimit, tt.wantLimit)
+			}
+			var body struct {
+				Scheduled []events.ScheduledMessage `json:"scheduled"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(body.Scheduled) != 1 || body.Scheduled[0].UUID != "m1" {
+				t.Errorf("scheduled = %+v", body.Scheduled)
+			}
+		})
+	}
+}
+
+func TestCancelScheduled(t *testing.T) {
+	tests := []struct {
+		name       string
+		cancelErr  error
+		wantStatus int
+	}{
+		{"cancelled", nil, http.StatusNoContent},
+		{"not found", events.ErrScheduledNotFound, http.StatusNotFound},
+		{"redis transport", events.ErrTransportUnsupported, http.StatusNotImplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeOffsets{cancelErr: tt.cancelErr}
+			w := httptest.NewRecorder()
+			newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/events/scheduled/m1", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if f.cancelled != "m1" {
+				t.Errorf("cancelled %q, want m1", f.cancelled)
+			}
+		})
+	}
+}
+
+func TestListDeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		dlqErr     error
+		wantStatus int
+		wantLimit  int
+	}{
+		{"default limit", "", nil, http.StatusOK, 100},
+		{"explicit limit", "?limit=5", nil, http.StatusOK, 5},
+		{"invalid limit", "?limit=0", nil, http.StatusBadRequest, 0},
+		{"invalid topic", "", fmt.Errorf("%w %q", events.ErrInvalidTopic, "x"), http.StatusBadRequest, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeOffsets{dlqErr: tt.dlqErr, deadLetters: []events.DeadLetter{
+				{UUID: "dl1", Topic: "item.created", Payload: []byte(`{}`), Attempts: 3},
+			}}
+			w := httptest.NewRecorder()
+			newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/topics/item.created/dlq"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if f.listTopic != "item.created" || f.dlqLimit != tt.wantLimit {
+				t.Errorf("listed topic %q limit %d, want item.created limit %d", f.listTopic, f.dlqLimit, tt.wantLimit)
+			}
+			var body struct {
+				DeadLetters []events.DeadLetter `json:"dead_letters"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(body.DeadLetters) != 1 || body.DeadLetters[0].Attempts != 3 {
+				t.Errorf("dead_letters = %+v", body.DeadLetters)
+			}
+		})
+	}
+}
+
+func TestGetDeadLetter(t *testing.T) {
+	f := &fakeOffsets{deadLetters: []events.DeadLetter{{UUID: "dl1", Topic: "item.created", Payload: []byte(`{}`)}}}
+
+	w := httptest.NewRecorder()
+	newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/topics/item.created/dlq/dl1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body)
+	}
+	var dl events.DeadLetter
+	if err := json.NewDecoder(w.Body).Decode(&dl); err != nil || dl.UUID != "dl1" {
+		t.Errorf("dead letter = %+v, %v", dl, err)
+	}
+
+	w = httptest.NewRecorder()
+	newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/events/topics/item.created/dlq/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing: status = %d, want 404", w.Code)
+	}
+}
+
+func TestRequeueDeadLetter(t *testing.T) {
+	tests := []struct {
+		name       string
+		dlqErr     error
+		wantStatus int
+	}{
+		{"requeued", nil, http.StatusNoContent},
+		{"not found", events.ErrDeadLetterNotFound, http.StatusNotFound},
+		{"failure", errors.New("db down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeOffsets{dlqErr: tt.dlqErr}
+			w := httptest.NewRecorder()
+			newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/events/topics/item.created/dlq/dl1/requeue", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body)
+			}
+			if f.requeued != "item.created/dl1" {
+				t.Errorf("requeued %q, want item.created/dl1", f.requeued)
+			}
+		})
+	}
+}
+
+func TestPurgeDeadLetters(t *testing.T) {
+	f := &fakeOffsets{deadLetters: make([]events.DeadLetter, 2)}
+	w := httptest.NewRecorder()
+	newRouter(f).ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/events/topics/item.created/dlq", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body)
+	}
+	if f.purgedTopic != "item.created" {
+		t.Errorf("purged %q, want item.created", f.purgedTopic)
+	}
+	var body struct {
+		Purged int64 `json:"purged"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil || body.Purged != 2 {
+		t.Errorf("purged = %d, %v; want 2", body.Purged, err)
+	}
+}
@@ -0,0 +1,69 @@
+// Package eventstest provides test helpers for event payload schemas.
+package eventstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/ghuser/ghproject/pkg/events"
+)
+
+// AssertUpcastable verifies that every version of T's payload accepted by typed
+// subscribers can be upcast to the current version and decoded into T.
+//
+// samples maps a schema version to a payload as it was published at that
+// version. A sample is required for every version below the current one, so a
+// gap in the upcaster chain fails whether or not a sample was provided for the
+// version it strands; one for the current version is optional. Decoding is
+// strict, so an upcaster that leaves behind renamed or removed fields fails:
+//
+//	func TestItemCreatedEvent_Upcast(t *testing.T) {
+//	    eventstest.AssertUpcastable[domainevents.ItemCreatedEvent](t, map[int][]byte{
+//	        1: []byte(`{"event_id":"...","item_id":"...","version":1}`),
+//	    })
+//	}
+func AssertUpcastable[T any](t testing.TB, samples map[int][]byte) {
+	t.Helper()
+
+	versions, err := events.VersionsOf[T]()
+	if err != nil {
+		t.Fatalf("versions: %v", err)
+	}
+	topic, err := events.TopicOf[T]()
+	if err != nil {
+		t.Fatalf("topic: %v", err)
+	}
+	current := versions[len(versions)-1]
+
+	for _, v := range slices.Sorted(maps.Keys(samples)) {
+		if !slices.Contains(versions, v) {
+			t.Errorf("%s: sample for version %d, which is not accepted (versions: %v)", topic, v, versions)
+		}
+	}
+
+	for _, v := range versions {
+		sample, ok := samples[v]
+		if !ok {
+			if v != current {
+				t.Errorf("%s: missing sample for version %d", topic, v)
+			}
+			continue
+		}
+
+		payload, err := events.Upcast(topic, v, sample)
+		if err != nil {
+			t.Errorf("%s: upcast from version %d: %v", topic, v, err)
+			continue
+		}
+
+		var event T
+		dec := json.NewDecoder(bytes.NewReader(payload))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&event); err != nil {
+			t.Errorf("%s: decode version %d upcast to %d: %v\npayload: %s", topic, v, current, err, payload)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package eventstest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ghuser/ghproject/pkg/events"
+)
+
+type renamedEvent struct {
+	Name string `json:"name"`
+}
+
+// gapEvent is at version 3 with an upcaster from 2 only: v1 messages are
+// rejected.
+type gapEvent struct {
+	Name string `json:"name"`
+}
+
+func init() {
+	events.Register[gapEvent]("eventstest.gap", 3)
+	events.RegisterUpcaster("eventstest.gap", 2, func(payload []byte) ([]byte, error) { return payload, nil })
+	events.Register[renamedEvent]("eventstest.renamed", 2)
+	events.RegisterUpcaster("eventstest.renamed", 1, func(payload []byte) ([]byte, error) {
+		return bytes.Replace(payload, []byte(`"title"`), []byte(`"name"`), 1), nil
+	})
+}
+
+// TestAssertUpcastable verifies the helper accepts a complete, correct chain.
+func TestAssertUpcastable(t *testing.T) {
+	AssertUpcastable[renamedEvent](t, map[int][]byte{
+		1: []byte(`{"title":"widget"}`),
+		2: []byte(`{"name":"widget"}`),
+	})
+}
+
+// recordingTB captures the failures of a helper under test.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// TestAssertUpcastable_ReportsGap verifies a version without an upcaster fails
+// the helper, whether or not a sample is provided for it.
+func TestAssertUpcastable_ReportsGap(t *testing.T) {
+	for name, samples := range map[string]map[int][]byte{
+		"with sample":    {1: []byte(`{"name":"widget"}`), 2: []byte(`{"name":"widget"}`)},
+		"without sample": {2: []byte(`{"name":"widget"}`)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := &recordingTB{TB: t}
+			AssertUpcastable[gapEvent](rec, samples)
+			if len(rec.errors) != 1 || !strings.Contains(rec.errors[0], "version 1") {
+				t.Errorf("errors: got %q, want one about version 1", rec.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package events
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// fakeDB is a database/sql driver that keeps the inbox and scheduled messages
+// tables, Watermill topic messages and consumer group positions, and the
+// consumer groups deleted from offsets tables in memory. It understands the
+// statements withInbox, CleanupInbox, DeliverDue, dropGroupWhenDone,
+// ResetOffset and Replay send. A
+// transaction works on a copy of the tables that Commit keeps and Rollback
+// discards, so tests can observe what a failed handler leaves behind.
+type fakeDB struct {
+	mu         sync.Mutex
+	tables     fakeTables
+	failTopics map[string]bool // Watermill topics whose inserts fail
+}
+
+type fakeTables struct {
+	inbox         map[inboxKey]time.Time // processed_at by (consumer group, event ID)
+	scheduled     []fakeScheduled
+	published     []string // UUIDs inserted into Watermill topic tables
+	droppedGroups []string // "{topic}/{group}" deleted from offsets tables
+	messages      []fakeMessage
+	offsets       map[string]position // by "{topic}/{group}"
+}
+
+// fakeMessage is a row of a Watermill topic table.
+type fakeMessage struct {
+	topic string
+	position
+	uuid string
+}
+
+func (m fakeMessage) after(p position) bool {
+	return comparePositions(m.position, p) > 0
+}
+
+// comparePositions orders positions as Postgres orders (xid8, bigint) rows.
+func comparePositions(a, b position) int {
+	ax, _ := strconv.ParseUint(a.txID, 10, 64)
+	bx, _ := strconv.ParseUint(b.txID, 10, 64)
+	return cmp.Or(cmp.Compare(ax, bx), cmp.Compare(a.offset, b.offset))
+}
+
+type fakeScheduled struct {
+	uuid, topic string
+	deliverAt   time.Time
+	attempts    int64
+	lastError   string
+	failed      bool
+}
+
+func (t fakeTables) clone() fakeTables {
+	return fakeTables{
+		inbox:         maps.Clone(t.inbox),
+		scheduled:     slices.Clone(t.scheduled),
+		published:     slices.Clone(t.published),
+		droppedGroups: slices.Clone(t.droppedGroups),
+		messages:      slices.Clone(t.messages),
+		offsets:       maps.Clone(t.offsets),
+	}
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		tables:     fakeTables{inbox: map[inboxKey]time.Time{}, offsets: map[string]position{}},
+		failTopics: map[string]bool{},
+	}
+}
+
+// newFakeBus returns a Postgres-transport EventBus on a fakeDB whose inbox and
+// scheduled messages tables already exist.
+func newFakeBus(t *testing.T) (*EventBus, *fakeDB) {
+	t.Helper()
+	f := newFakeDB()
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { _ = db.Close() })
+	q := &EventBus{
+		db:            db,
+		log:           logger.New(&config.Config{LogLevel: "error"}),
+		serviceName:   "svc",
+		consumerGroup: "svc-consumer",
+		metrics:       nopBusMetrics(),
+	}
+	q.scheduleReady.Store(true)
+	return q, f
+}
+
+// snapshot returns a copy of the committed tables.
+func (f *fakeDB) snapshot() fakeTables {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.tables.clone()
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+// fakeConn runs statements against its transaction's copy of the tables, or
+// against the committed tables outside a transaction.
+type fakeConn struct {
+	db        *fakeDB
+	tx        *fakeTables
+	savepoint *fakeTables
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	tables := c.db.snapshot()
+	c.tx = &tables
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.tables, c.tx, c.savepoint = *c.tx, nil, nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.tx, c.savepoint = nil, nil
+	return nil
+}
+
+// CheckNamedValue passes string slices through, as pgx does, and converts
+// other arguments as database/sql does by default.
+func (c *fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if _, ok := nv.Value.([]string); ok {
+		return nil
+	}
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	nv.Value = v
+	return err
+}
+
+// tables returns the tables statements run against. c.db.mu must be held.
+func (c *fakeConn) tables() *fakeTables {
+	if c.tx != nil {
+		return c.tx
+	}
+	return &c.db.tables
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	t := c.tables()
+	switch {
+	case strings.HasPrefix(query, "SAVEPOINT"):
+		sp := t.clone()
+		c.savepoint = &sp
+	case strings.HasPrefix(query, "RELEASE SAVEPOINT"):
+		c.savepoint = nil
+	case strings.HasPrefix(query, "ROLLBACK TO SAVEPOINT"):
+		*t = c.savepoint.clone()
+	case strings.HasPrefix(query, `INSERT INTO "watermill_offsets_`):
+		topic := tableTopic(query, `INSERT INTO "watermill_offsets_`)
+		t.offsets[topic+"/"+args[0].Value.(string)] = position{txID: args[2].Value.(string), offset: args[1].Value.(int64)}
+	case strings.HasPrefix(query, `INSERT INTO "watermill_`):
+		topic := tableTopic(query, `INSERT INTO "watermill_`)
+		if c.db.failTopics[topic] {
+			return nil, fmt.Errorf(`relation "watermill_%s" does not exist`, topic)
+		}
+		t.published = append(t.published, args[0].Value.(string))
+	case strings.Contains(query, "INSERT INTO "+inboxTable):
+		key := inboxKey{subscription: args[0].Value.(string), eventID: args[1].Value.(string)}
+		if _, ok := t.inbox[key]; ok {
+			return driver.RowsAffected(0), nil
+		}
+		t.inbox[key] = time.Now()
+	case strings.Contains(query, "DELETE FROM "+inboxTable):
+		cutoff := args[0].Value.(time.Time)
+		n := len(t.inbox)
+		maps.DeleteFunc(t.inbox, func(_ inboxKey, processedAt time.Time) bool { return processedAt.Before(cutoff) })
+		return driver.RowsAffected(n - len(t.inbox)), nil
+	case strings.HasPrefix(query, `DELETE FROM "watermill_offsets_`):
+		topic := tableTopic(query, `DELETE FROM "watermill_offsets_`)
+		t.droppedGroups = append(t.droppedGroups, topic+"/"+args[0].Value.(string))
+	case strings.Contains(query, "DELETE FROM "+scheduledTable):
+		uuids := args[0].Value.([]string)
+		n := len(t.scheduled)
+		t.scheduled = slices.DeleteFunc(t.scheduled, func(s fakeScheduled) bool { return slices.Contains(uuids, s.uuid) })
+		return driver.RowsAffected(n - len(t.scheduled)), nil
+	default:
+		return nil, fmt.Errorf("unexpected exec: %s", query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	t := c.tables()
+	switch {
+	case strings.Contains(query, "FOR UPDATE SKIP LOCKED"):
+		rows := &fakeRows{columns: strings.Split(scheduledColumns, ", ")}
+		due := slices.Clone(t.scheduled)
+		slices.SortFunc(due, func(a, b fakeScheduled) int {
+			return cmp.Or(a.deliverAt.Compare(b.deliverAt), strings.Compare(a.uuid, b.uuid))
+		})
+		for _, s := range due {
+			if !s.failed && !s.deliverAt.After(time.Now()) && len(rows.values) < int(args[0].Value.(int64)) {
+				rows.values = append(rows.values, []driver.Value{
+					s.uuid, s.topic, []byte("{}"), []byte(`{"k":"v"}`), s.deliverAt, s.deliverAt, s.attempts, nil, nil,
+				})
+			}
+		}
+		return rows, nil
+	case strings.Contains(query, "SET attempts"):
+		uuid, lastError, maxAttempts := args[0].Value.(string), args[1].Value.(string), args[2].Value.(int64)
+		for i := range t.scheduled {
+			if s := &t.scheduled[i]; s.uuid == uuid {
+				s.attempts++
+				s.lastError = lastError
+				s.failed = s.attempts >= maxAttempts
+				return &fakeRows{columns: []string{"attempts", "failed"}, values: [][]driver.Value{{s.attempts, s.failed}}}, nil
+			}
+		}
+		return &fakeRows{}, nil
+	case strings.Contains(query, `WHERE "offset" = $1`):
+		topic := tableTopic(query, `SELECT transaction_id::text FROM "watermill_`)
+		rows := &fakeRows{columns: []string{"transaction_id"}}
+		for _, m := range t.messages {
+			if m.topic == topic && m.offset == args[0].Value.(int64) {
+				rows.values = append(rows.values, []driver.Value{m.txID})
+			}
+		}
+		return rows, nil
+	case strings.Contains(query, `ORDER BY transaction_id, "offset"`):
+		topic := tableTopic(strings.TrimSpace(query), `SELECT transaction_id::text, "offset", uuid, payload, metadata FROM "watermill_`)
+		after := position{txID: args[0].Value.(string), offset: args[1].Value.(int64)}
+		last := position{txID: args[2].Value.(string), offset: args[3].Value.(int64)}
+		messages := slices.Clone(t.messages)
+		slices.SortFunc(messages, func(a, b fakeMessage) int { return comparePositions(a.position, b.position) })
+		rows := &fakeRows{columns: []string{"transaction_id", "offset", "uuid", "payload", "metadata"}}
+		for _, m := range messages {
+			if m.topic == topic && m.after(after) && !m.after(last) && len(rows.values) < int(args[4].Value.(int64)) {
+				rows.values = append(rows.values, []driver.Value{m.txID, m.offset, m.uuid, []byte("{}"), nil})
+			}
+		}
+		return rows, nil
+	case strings.Contains(query, "starts_with(consumer_group, $1)"):
+		topic := tableTopic(query, `SELECT consumer_group FROM "watermill_offsets_`)
+		rows := &fakeRows{columns: []string{"consumer_group"}}
+		for _, key := range slices.Sorted(maps.Keys(t.offsets)) {
+			if group, ok := strings.CutPrefix(key, topic+"/"); ok && strings.HasPrefix(group, args[0].Value.(string)) {
+				rows.values = append(rows.values, []driver.Value{group})
+			}
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+}
+
+// tableTopic returns the topic of the Watermill table quoted right after prefix.
+func tableTopic(query, prefix string) string {
+	topic := strings.TrimPrefix(query, prefix)
+	return topic[:strings.IndexByte(topic, '"')]
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -0,0 +1,134 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/ghuser/ghproject/pkg/database"
+)
+
+// inboxTable records which events each consumer group has already processed.
+// Created on first use by a WithInbox subscription, like Watermill's own tables.
+const inboxTable = "events_inbox"
+
+type txContextKey struct{}
+
+// TxFromContext returns the inbox transaction a WithInbox handler is running in.
+// Returns false for handlers subscribed without WithInbox.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// contextWithTx carries tx for TxFromContext and for pkg/database, so
+// repositories called by the handler join the inbox transaction.
+func contextWithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return database.ContextWithTx(context.WithValue(ctx, txContextKey{}, tx), tx)
+}
+
+// eventIDOf returns the deduplication key for msg.
+func eventIDOf(msg *message.Message) string {
+	if id := msg.Metadata.Get(MetaEventID); id != "" {
+		return id
+	}
+	return msg.UUID
+}
+
+// initInbox creates the inbox table if it does not exist.
+func (q *EventBus) initInbox(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+inboxTable+` (
+			consumer_group TEXT        NOT NULL,
+			event_id       TEXT        NOT NULL,
+			topic          TEXT        NOT NULL,
+			processed_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (consumer_group, event_id)
+		);
+		CREATE INDEX IF NOT EXISTS `+inboxTable+`_processed_at_idx ON `+inboxTable+` (processed_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("events: init inbox: %w", err)
+	}
+	return nil
+}
+
+// withInbox wraps handler so each event is processed at most once per consumer group.
+// consumerGroup is the subscription's group, so broadcast subscriptions on
+// different instances each process the event once.
+// Concurrent deliveries of the same event serialize on the inbox primary key:
+// the second INSERT waits for the first transaction and then inserts nothing.
+func (q *EventBus) withInbox(topic, consumerGroup string, handler func(context.Context, *message.Message) error) func(context.Context, *message.Message) error {
+	return func(ctx context.Context, msg *message.Message) error {
+		eventID := eventIDOf(msg)
+
+		tx, err := q.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("events: begin inbox tx: %w", err)
+		}
+		defer tx.Rollback() //nolint:errcheck
+
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO `+inboxTable+` (consumer_group, event_id, topic) VALUES ($1, $2, $3)
+			 ON CONFLICT (consumer_group, event_id) DO NOTHING`,
+			consumerGroup, eventID, topic,
+		)
+		if err != nil {
+			return fmt.Errorf("events: record inbox entry: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			q.log.DebugContext(ctx, "events: duplicate event skipped",
+				"topic", topic, "event_id", eventID, "consumer_group", consumerGroup)
+			return nil
+		}
+
+		if err := handler(contextWithTx(ctx, tx), msg); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("events: commit inbox tx: %w", err)
+		}
+		return nil
+	}
+}
+
+// CleanupInbox deletes inbox entries processed more than retention ago and
+// returns how many were removed. Redeliveries older than retention are no
+// longer deduplicated, so keep it well above the longest redelivery window.
+func (q *EventBus) CleanupInbox(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := q.db.ExecContext(ctx,
+		`DELETE FROM `+inboxTable+` WHERE processed_at < $1`,
+		time.Now().Add(-retention),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("events: cleanup inbox: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+// RunInboxCleanup calls CleanupInbox every interval until ctx is cancelled.
+// Failures are logged and retried on the next tick.
+func (q *EventBus) RunInboxCleanup(ctx context.Context, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := q.CleanupInbox(ctx, retention)
+			if err != nil {
+				q.log.ErrorContext(ctx, "events: inbox cleanup failed", "error", err)
+				continue
+			}
+			if n > 0 {
+				q.log.InfoContext(ctx, "events: inbox cleaned up", "deleted", n, "retention", retention)
+			}
+		}
+	}
+}
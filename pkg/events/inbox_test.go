@@ -0,0 +1,139 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// TestSubscribeConfig_Inbox verifies WithInbox is opt-in.
+func TestSubscribeConfig_Inbox(t *testing.T) {
+	if newSubscribeConfig(nil).inbox {
+		t.Error("expected inbox disabled by default")
+	}
+	if !newSubscribeConfig([]SubscribeOption{WithInbox()}).inbox {
+		t.Error("expected inbox enabled with WithInbox")
+	}
+}
+
+// TestEventIDOf verifies event_id metadata is preferred over the message UUID.
+func TestEventIDOf(t *testing.T) {
+	msg := message.NewMessage("msg-uuid", nil)
+	if got := eventIDOf(msg); got != "msg-uuid" {
+		t.Errorf("fallback: got %q, want %q", got, "msg-uuid")
+	}
+
+	msg.Metadata.Set(MetaEventID, "evt-1")
+	if got := eventIDOf(msg); got != "evt-1" {
+		t.Errorf("event_id: got %q, want %q", got, "evt-1")
+	}
+}
+
+// TestTxFromContext verifies the inbox transaction round-trips through the context.
+func TestTxFromContext(t *testing.T) {
+	if _, ok := TxFromContext(context.Background()); ok {
+		t.Error("expected no tx in empty context")
+	}
+
+	tx := &sql.Tx{}
+	got, ok := TxFromContext(contextWithTx(context.Background(), tx))
+	if !ok || got != tx {
+		t.Errorf("expected tx %p, got %p (ok=%v)", tx, got, ok)
+	}
+}
+
+// TestWithInbox_SkipsRedelivery verifies an event processed successfully is
+// acknowledged without running the handler again when redelivered to the
+// same consumer group, but still runs for another group.
+func TestWithInbox_SkipsRedelivery(t *testing.T) {
+	q, f := newFakeBus(t)
+	calls := 0
+	handler := func(ctx context.Context, _ *message.Message) error {
+		if _, ok := TxFromContext(ctx); !ok {
+			t.Error("handler not run in the inbox transaction")
+		}
+		calls++
+		return nil
+	}
+	ctx := context.Background()
+	msg := message.NewMessage("msg-1", nil)
+	msg.Metadata.Set(MetaEventID, "evt-1")
+	redelivered := message.NewMessage("msg-2", nil)
+	redelivered.Metadata.Set(MetaEventID, "evt-1")
+
+	inbox := q.withInbox("item.created", "svc-consumer", handler)
+	for _, m := range []*message.Message{msg, redelivered} {
+		if err := inbox(ctx, m); err != nil {
+			t.Fatalf("deliver %s: %v", m.UUID, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times for one event, want 1", calls)
+	}
+
+	if err := q.withInbox("item.created", "audit", handler)(ctx, redelivered); err != nil {
+		t.Fatalf("deliver to another group: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want once more for another group", calls)
+	}
+	if n := len(f.snapshot().inbox); n != 2 {
+		t.Errorf("inbox rows: got %d, want 2", n)
+	}
+}
+
+// TestWithInbox_HandlerErrorRollsBack verifies a failed handler leaves no
+// inbox row, so the next attempt runs it again.
+func TestWithInbox_HandlerErrorRollsBack(t *testing.T) {
+	q, f := newFakeBus(t)
+	fail := true
+	calls := 0
+	inbox := q.withInbox("item.created", "svc-consumer", func(context.Context, *message.Message) error {
+		calls++
+		if fail {
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	ctx := context.Background()
+	msg := message.NewMessage("msg-1", nil)
+
+	if err := inbox(ctx, msg); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	if n := len(f.snapshot().inbox); n != 0 {
+		t.Fatalf("inbox rows after a failed attempt: got %d, want 0", n)
+	}
+
+	fail = false
+	if err := inbox(ctx, msg); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+	if n := len(f.snapshot().inbox); n != 1 {
+		t.Errorf("inbox rows after success: got %d, want 1", n)
+	}
+}
+
+// TestCleanupInbox verifies only entries older than the retention are removed.
+func TestCleanupInbox(t *testing.T) {
+	q, f := newFakeBus(t)
+	now := time.Now()
+	f.tables.inbox[inboxKey{subscription: "svc-consumer", eventID: "old"}] = now.Add(-48 * time.Hour)
+	f.tables.inbox[inboxKey{subscription: "svc-consumer", eventID: "recent"}] = now.Add(-time.Hour)
+
+	n, err := q.CleanupInbox(context.Background(), 24*time.Hour)
+	if err != nil || n != 1 {
+		t.Fatalf("CleanupInbox: got %d, %v; want 1 removed", n, err)
+	}
+	inbox := f.snapshot().inbox
+	if _, ok := inbox[inboxKey{subscription: "svc-consumer", eventID: "recent"}]; !ok || len(inbox) != 1 {
+		t.Errorf("inbox after cleanup: %v, want only the recent entry", inbox)
+	}
+}
@@ -0,0 +1,208 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// ErrBusClosed is returned by a MemoryBus after Close.
+var ErrBusClosed = errors.New("events: bus closed")
+
+// memoryQueueSize is how many messages a MemoryBus subscription queues before
+// Publish blocks.
+const memoryQueueSize = 100
+
+// MemoryBus is an in-process Bus backed by Watermill's gochannel, for tests and
+// local development. Retries, dead-lettering (to <topic>.dlq on the same bus)
+// and trace propagation behave as on EventBus. Differences:
+//   - Nothing is persisted; messages published with no subscriber are dropped.
+//   - Publish returns once every subscription has queued the message, and a
+//     queued message is not redelivered after a Nack.
+//   - Every subscription to a topic receives every message: WithConsumerGroup is
+//     ignored and WithBroadcast is the only delivery mode.
+//   - NewTxPublisher publishes immediately and ignores the transaction, so a
+//     rolled-back transaction does not retract its messages.
+//   - WithInbox deduplicates in memory per subscription, keyed by handler name
+//     since every subscription receives every message, and TxFromContext
+//     returns no transaction.
+type MemoryBus struct {
+	pubsub  *gochannel.GoChannel
+	log     logger.Logger
+	wg      sync.WaitGroup
+	closed  atomic.Bool
+	metrics *busMetrics
+
+	mu        sync.Mutex
+	processed map[inboxKey]struct{} // events handled by WithInbox subscriptions
+}
+
+// inboxKey identifies an event handled by one MemoryBus subscription, as
+// (consumer_group, event_id) does in the PostgreSQL inbox.
+type inboxKey struct {
+	subscription string // handler name
+	eventID      string
+}
+
+// NewMemoryBus creates an empty in-memory bus.
+func NewMemoryBus(log logger.Logger) *MemoryBus {
+	metrics, err := newBusMetrics()
+	if err != nil {
+		log.Warn("events: memory bus metrics disabled", "error", err)
+		metrics = nopBusMetrics()
+	}
+	return &MemoryBus{
+		pubsub: gochannel.NewGoChannel(gochannel.Config{
+			OutputChannelBuffer:            memoryQueueSize,
+			BlockPublishUntilSubscriberAck: true, // keeps one publisher's messages in order
+		}, &slogAdapter{log: log}),
+		log:       log,
+		metrics:   metrics,
+		processed: map[inboxKey]struct{}{},
+	}
+}
+
+// Publish sends msgs to topic, injecting the OTel trace context from ctx.
+func (b *MemoryBus) Publish(ctx context.Context, topic string, msgs ...*message.Message) error {
+	if b.closed.Load() {
+		return ErrBusClosed
+	}
+	injectTrace(ctx, msgs)
+	if err := b.publisher().Publish(topic, msgs...); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe registers handler to process messages from topic with the same
+// Ack/Nack, retry and dead-letter behavior as EventBus.Subscribe.
+func (b *MemoryBus) Subscribe(
+	ctx context.Context,
+	topic string,
+	handler func(context.Context, *message.Message) error,
+	opts ...SubscribeOption,
+) (<-chan error, error) {
+	if b.closed.Load() {
+		return nil, ErrBusClosed
+	}
+	cfg := newSubscribeConfig(opts)
+	name := cfg.handlerName
+	if name == "" {
+		name = handlerName(handler)
+	}
+	if cfg.inbox {
+		handler = b.withInbox(name, handler)
+	}
+
+	return subscribePartitioned(ctx, b.log, topic, cfg,
+		func(ctx context.Context, _ partition) (<-chan *message.Message, error) {
+			ch, err := b.pubsub.Subscribe(ctx, topic)
+			if err != nil {
+				return nil, fmt.Errorf("events: subscribe to %s: %w", topic, err)
+			}
+			return b.queue(ch), nil
+		},
+		func(ctx context.Context, msgs <-chan *message.Message, part partition) <-chan error {
+			return deliver(ctx, &b.wg, b.log, b.publisher(), b.metrics, topic, cfg.consumerGroup, name, msgs, handler, cfg, part)
+		},
+	)
+}
+
+// queue Acks messages as soon as they arrive from the pubsub and passes copies
+// to the delivery loop in arrival order. gochannel hands each published message
+// to subscribers in its own goroutine; acknowledging on arrival lets Publish
+// wait for delivery without waiting for the handler.
+func (b *MemoryBus) queue(in <-chan *message.Message) <-chan *message.Message {
+	out := make(chan *message.Message, memoryQueueSize)
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer close(out)
+		for msg := range in {
+			out <- msg.Copy()
+			msg.Ack()
+		}
+	}()
+	return out
+}
+
+// withInbox skips events whose ID was already handled successfully by the
+// subscription named subscription. Unlike the PostgreSQL inbox, concurrent
+// deliveries of the same event are not serialized; tests that need that
+// guarantee should use EventBus.
+func (b *MemoryBus) withInbox(
+	subscription string,
+	handler func(context.Context, *message.Message) error,
+) func(context.Context, *message.Message) error {
+	return func(ctx context.Context, msg *message.Message) error {
+		key := inboxKey{subscription: subscription, eventID: eventIDOf(msg)}
+		b.mu.Lock()
+		_, seen := b.processed[key]
+		b.mu.Unlock()
+		if seen {
+			return nil
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		b.processed[key] = struct{}{}
+		b.mu.Unlock()
+		return nil
+	}
+}
+
+// NewTxPublisher returns a publisher that publishes straight to the bus.
+// tx is ignored (and may be nil); see MemoryBus for the implications.
+func (b *MemoryBus) NewTxPublisher(_ *sql.Tx) (message.Publisher, error) {
+	if b.closed.Load() {
+		return nil, ErrBusClosed
+	}
+	return memoryTxPublisher{b}, nil
+}
+
+// memoryTxPublisher publishes to the bus without closing it on Close.
+type memoryTxPublisher struct{ bus *MemoryBus }
+
+func (p memoryTxPublisher) Publish(topic string, msgs ...*message.Message) error {
+	if p.bus.closed.Load() {
+		return ErrBusClosed
+	}
+	return p.bus.publisher().Publish(topic, msgs...)
+}
+
+func (p memoryTxPublisher) Close() error { return nil }
+
+// publisher returns the pubsub with publish metrics.
+func (b *MemoryBus) publisher() message.Publisher {
+	return meteredPublisher{next: b.pubsub, metrics: b.metrics}
+}
+
+// Ping returns ErrBusClosed after Close and nil otherwise.
+func (b *MemoryBus) Ping(_ context.Context) error {
+	if b.closed.Load() {
+		return ErrBusClosed
+	}
+	return nil
+}
+
+// Close stops all subscriptions and waits for in-flight handlers to complete.
+func (b *MemoryBus) Close() error {
+	if !b.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	if err := b.pubsub.Close(); err != nil {
+		return fmt.Errorf("events: close memory bus: %w", err)
+	}
+	b.wg.Wait()
+	return nil
+}
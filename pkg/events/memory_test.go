@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestMemoryBus_PublishSubscribe verifies delivery and trace propagation.
+func TestMemoryBus_PublishSubscribe(t *testing.T) {
+	tp := setupTracer()
+	defer tp.Shutdown(context.Background()) //nolint:errcheck
+
+	bus := NewMemoryBus(nopLogger())
+	defer bus.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan trace.SpanContext, 1)
+	errCh, err := bus.Subscribe(ctx, "test.topic", func(ctx context.Context, _ *message.Message) error {
+		got <- trace.SpanContextFromContext(ctx)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	go func() {
+		for range errCh {
+		}
+	}()
+
+	pubCtx, span := tp.Tracer("test").Start(context.Background(), "publish")
+	defer span.End()
+	if err := bus.Publish(pubCtx, "test.topic", message.NewMessage("id", []byte("{}"))); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	select {
+	case sc := <-got:
+		if sc.TraceID() != span.SpanContext().TraceID() {
+			t.Errorf("trace ID: got %s, want %s", sc.TraceID(), span.SpanContext().TraceID())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+}
+
+// TestMemoryBus_Closed verifies operations fail with ErrBusClosed after Close.
+func TestMemoryBus_Closed(t *testing.T) {
+	bus := NewMemoryBus(nopLogger())
+	if err := bus.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := bus.Close(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+
+	if err := bus.Ping(context.Background()); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("ping: expected ErrBusClosed, got %v", err)
+	}
+	if err := bus.Publish(context.Background(), "t", message.NewMessage("id", nil)); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("publish: expected ErrBusClosed, got %v", err)
+	}
+	if _, err := bus.NewTxPublisher(nil); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("tx publisher: expected ErrBusClosed, got %v", err)
+	}
+}
+
+// TestMemoryBus_InboxPerSubscription verifies WithInbox deduplicates per
+// subscription: a redelivered event is skipped by each handler that already
+// handled it, and not hidden from another handler on the same topic.
+func TestMemoryBus_InboxPerSubscription(t *testing.T) {
+	bus := NewMemoryBus(nopLogger())
+	defer bus.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan string, 10)
+	for _, name := range []string{"first", "second"} {
+		errCh, err := bus.Subscribe(ctx, "test.inbox", func(_ context.Context, msg *message.Message) error {
+			got <- name + " " + msg.UUID
+			return nil
+		}, WithInbox(), WithHandlerName(name))
+		if err != nil {
+			t.Fatalf("subscribe %s: %v", name, err)
+		}
+		go func() {
+			for range errCh {
+			}
+		}()
+	}
+
+	for _, id := range []string{"a", "a", "b"} {
+		if err := bus.Publish(ctx, "test.inbox", message.NewMessage(id, []byte("{}"))); err != nil {
+			t.Fatalf("publish %s: %v", id, err)
+		}
+	}
+
+	counts := map[string]int{}
+	for range 4 {
+		select {
+		case delivery := <-got:
+			counts[delivery]++
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for delivery; got %v", counts)
+		}
+	}
+	select {
+	case delivery := <-got:
+		t.Errorf("duplicate delivery %q; got %v", delivery, counts)
+	case <-time.After(100 * time.Millisecond):
+	}
+	for _, want := range []string{"first a", "first b", "second a", "second b"} {
+		if counts[want] != 1 {
+			t.Errorf("%s delivered %d times, want 1; got %v", want, counts[want], counts)
+		}
+	}
+}
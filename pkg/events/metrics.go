@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// lagObserveTimeout bounds the offsets queries behind one lag observation.
+const lagObserveTimeout = 5 * time.Second
+
+// busMetrics are the instruments a Bus records delivery outcomes into. Handler
+// attempt counts and durations come from the Metrics middleware.
+type busMetrics struct {
+	published    metric.Int64Counter // events.published{topic, outcome}
+	retries      metric.Int64Counter // events.handler.retries{topic, consumer_group}
+	failures     metric.Int64Counter // events.handler.failures{topic, consumer_group}
+	deadLettered metric.Int64Counter // events.dead_lettered{topic, consumer_group}
+
+	scheduledFailed metric.Int64Counter // events.scheduled.failed{topic}
+}
+
+// newBusMetrics creates the bus instruments on the global OTel meter provider,
+// which telemetry.Setup exports on /metrics.
+func newBusMetrics() (*busMetrics, error) {
+	return newBusMetricsFrom(otel.Meter(instrumentationName))
+}
+
+// nopBusMetrics returns instruments that record nothing.
+func nopBusMetrics() *busMetrics {
+	m, _ := newBusMetricsFrom(noop.NewMeterProvider().Meter(instrumentationName))
+	return m
+}
+
+func newBusMetricsFrom(meter metric.Meter) (*busMetrics, error) {
+	var (
+		m   busMetrics
+		err error
+	)
+	for _, c := range []struct {
+		dst        *metric.Int64Counter
+		name, desc string
+		unit       string
+	}{
+		{&m.published, "events.published", "Messages published, by topic and outcome.", "{message}"},
+		{&m.retries, "events.handler.retries", "Handler attempts after the first for a message.", "{attempt}"},
+		{&m.failures, "events.handler.failures", "Messages whose handler failed after all attempts.", "{message}"},
+		{&m.deadLettered, "events.dead_lettered", "Messages moved to a dead-letter topic.", "{message}"},
+		{&m.scheduledFailed, "events.scheduled.failed", "Scheduled messages marked failed after repeated delivery failures.", "{message}"},
+	} {
+		if *c.dst, err = meter.Int64Counter(c.name, metric.WithDescription(c.desc), metric.WithUnit(c.unit)); err != nil {
+			return nil, fmt.Errorf("events: %s counter: %w", c.name, err)
+		}
+	}
+	return &m, nil
+}
+
+// recordDelivery records the outcome of one message's delivery. failures is
+// the number of failed attempts; handled reports whether the last one succeeded.
+func (m *busMetrics) recordDelivery(ctx context.Context, d Delivery, failures int, handled, deadLettered bool) {
+	attrs := metric.WithAttributes(
+		attribute.String("topic", d.Topic),
+		attribute.String("consumer_group", d.ConsumerGroup),
+	)
+	retries := failures
+	if !handled {
+		retries-- // the last failure was not followed by another attempt
+	}
+	if retries > 0 {
+		m.retries.Add(ctx, int64(retries), attrs)
+	}
+	if !handled {
+		m.failures.Add(ctx, 1, attrs)
+	}
+	if deadLettered {
+		m.deadLettered.Add(ctx, 1, attrs)
+	}
+}
+
+// meteredPublisher counts published messages by topic and outcome.
+type meteredPublisher struct {
+	next    message.Publisher
+	metrics *busMetrics
+}
+
+func (p meteredPublisher) Publish(topic string, msgs ...*message.Message) error {
+	err := p.next.Publish(topic, msgs...)
+	outcome := "success"
+	if err != nil {
+		outcome = "failure"
+	}
+	p.metrics.published.Add(context.Background(), int64(len(msgs)), metric.WithAttributes(
+		attribute.String("topic", topic),
+		attribute.String("outcome", outcome),
+	))
+	return err
+}
+
+func (p meteredPublisher) Close() error { return p.next.Close() }
+
+// RegisterLagMetrics reports consumer lag and forwarder queue depth as OTel
+// gauges, computed from the Watermill offsets tables on every collection:
+//
+//   - events.consumer.lag{topic, consumer_group}: messages not yet acknowledged
+//   - events.forwarder.queue_depth: messages waiting in the forwarder queue
+//
+// Each collection counts unprocessed rows of every topic, so register it in
+// one process type (the API, which serves /metrics) rather than in every
+// process. Close unregisters it. Returns ErrTransportUnsupported on the Redis
+// transport.
+func (q *EventBus) RegisterLagMetrics() error {
+	if q.redis != nil {
+		return ErrTransportUnsupported
+	}
+	meter := otel.Meter(instrumentationName)
+	lag, err := meter.Int64ObservableGauge("events.consumer.lag",
+		metric.WithDescription("Messages published to a topic and not yet acknowledged by a consumer group."),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return fmt.Errorf("events: lag gauge: %w", err)
+	}
+	depth, err := meter.Int64ObservableGauge("events.forwarder.queue_depth",
+		metric.WithDescription("Messages in the forwarder queue not yet forwarded to their topic."),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return fmt.Errorf("events: queue depth gauge: %w", err)
+	}
+
+	reg, err := meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		ctx, cancel := context.WithTimeout(ctx, lagObserveTimeout)
+		defer cancel()
+
+		groups, err := q.ConsumerGroups(ctx, "")
+		if err != nil {
+			q.log.WarnContext(ctx, "events: observe consumer lag failed", "error", err)
+			return nil // a failed observation must not fail the whole collection
+		}
+		for _, g := range groups {
+			if g.Topic == forwarderTopic {
+				o.ObserveInt64(depth, g.Lag)
+				continue
+			}
+			o.ObserveInt64(lag, g.Lag, metric.WithAttributes(
+				attribute.String("topic", g.Topic),
+				attribute.String("consumer_group", g.ConsumerGroup),
+			))
+		}
+		return nil
+	}, lag, depth)
+	if err != nil {
+		return fmt.Errorf("events: register lag callback: %w", err)
+	}
+
+	q.mu.Lock()
+	q.lagMetrics = reg
+	q.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// counterTotals sums every int64 counter collected by reader, by name.
+func counterTotals(t *testing.T, reader sdkmetric.Reader) map[string]int64 {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	totals := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if sum, ok := m.Data.(metricdata.Sum[int64]); ok {
+				for _, dp := range sum.DataPoints {
+					totals[m.Name] += dp.Value
+				}
+			}
+		}
+	}
+	return totals
+}
+
+// TestBusMetrics_Delivery verifies publish, retry, failure and dead-letter counts.
+func TestBusMetrics_Delivery(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	metrics, err := newBusMetricsFrom(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"))
+	if err != nil {
+		t.Fatalf("newBusMetricsFrom: %v", err)
+	}
+
+	bus := NewMemoryBus(nopLogger())
+	bus.metrics = metrics
+	defer bus.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{}, 2)
+	calls := 0
+	errCh, err := bus.Subscribe(ctx, "test.metrics", func(_ context.Context, msg *message.Message) error {
+		calls++
+		if msg.UUID == "flaky" && calls == 1 {
+			return errors.New("transient")
+		}
+		defer func() { done <- struct{}{} }()
+		if msg.UUID == "poison" {
+			return Permanent(errors.New("malformed"))
+		}
+		return nil
+	}, WithRetryPolicy(RetryPolicy{BaseDelay: time.Millisecond}))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	go func() {
+		for range errCh {
+		}
+	}()
+
+	for _, id := range []string{"flaky", "poison"} {
+		if err := bus.Publish(ctx, "test.metrics", message.NewMessage(id, []byte("{}"))); err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for delivery")
+		}
+	}
+	// The poison message reaches the DLQ after its handler returns.
+	waitFor(t, "the dead letter", func() bool { return counterTotals(t, reader)["events.dead_lettered"] == 1 })
+
+	want := map[string]int64{
+		"events.published":        3, // two messages and one dead letter
+		"events.handler.retries":  1,
+		"events.handler.failures": 1,
+		"events.dead_lettered":    1,
+	}
+	got := counterTotals(t, reader)
+	for name, n := range want {
+		if got[name] != n {
+			t.Errorf("%s: got %d, want %d", name, got[name], n)
+		}
+	}
+}
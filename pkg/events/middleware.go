@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+const instrumentationName = "github.com/ghuser/ghproject/pkg/events"
+
+// Handler processes a single delivery attempt of a message.
+type Handler = func(context.Context, *message.Message) error
+
+// Middleware wraps a Handler with cross-cutting behavior. It runs once per
+// attempt, so a retried message passes through it several times.
+type Middleware func(Handler) Handler
+
+// Delivery describes the message a handler is processing.
+// Middleware reads it with DeliveryFromContext to label logs, spans and metrics.
+type Delivery struct {
+	Topic         string
+	ConsumerGroup string // empty on MemoryBus without WithConsumerGroup
+	Handler       string // same name as recorded in dead-letter metadata
+	Attempt       int    // 1 for the first call
+}
+
+type deliveryContextKey struct{}
+
+// DeliveryFromContext returns the delivery a handler is running for.
+// Returns false outside a subscription handler.
+func DeliveryFromContext(ctx context.Context) (Delivery, bool) {
+	d, ok := ctx.Value(deliveryContextKey{}).(Delivery)
+	return d, ok
+}
+
+func contextWithDelivery(ctx context.Context, d Delivery) context.Context {
+	return context.WithValue(ctx, deliveryContextKey{}, d)
+}
+
+func contextWithAttempt(ctx context.Context, attempt int) context.Context {
+	d, _ := DeliveryFromContext(ctx)
+	d.Attempt = attempt
+	return contextWithDelivery(ctx, d)
+}
+
+// chain wraps handler so the first middleware is the outermost, matching the
+// order of chi's Use.
+func chain(handler Handler, mws []Middleware) Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		handler = mws[i](handler)
+	}
+	return handler
+}
+
+// DefaultMiddleware returns the standard handler stack, outermost first:
+// Tracing → Metrics → Logging → Recoverer. Recoverer is innermost so the
+// others observe a panic as a failed attempt.
+func DefaultMiddleware(log logger.Logger) ([]Middleware, error) {
+	metrics, err := Metrics()
+	if err != nil {
+		return nil, err
+	}
+	return []Middleware{Tracing(), metrics, Logging(log), Recoverer(log)}, nil
+}
+
+// Recoverer turns a handler panic into an error, so the attempt is retried and
+// eventually dead-lettered instead of crashing the process. The stack is logged.
+func Recoverer(log logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *message.Message) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.ErrorContext(ctx, "events: handler panic recovered",
+						"error", r,
+						"message_uuid", msg.UUID,
+						"stack", string(debug.Stack()),
+					)
+					err = fmt.Errorf("events: handler panicked: %v", r)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Logging logs every attempt with its duration: successes at Debug, failures at Warn.
+func Logging(log logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *message.Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			d, _ := DeliveryFromContext(ctx)
+			args := []any{
+				"topic", d.Topic,
+				"consumer_group", d.ConsumerGroup,
+				"handler", d.Handler,
+				"attempt", d.Attempt,
+				"message_uuid", msg.UUID,
+				"latency_ms", time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				log.WarnContext(ctx, "events: handler attempt failed", append(args, "error", err)...)
+			} else {
+				log.DebugContext(ctx, "events: message handled", args...)
+			}
+			return err
+		}
+	}
+}
+
+// Tracing starts a consumer span for every attempt. The span is a child of the
+// publisher's trace, which the bus restores from message metadata.
+func Tracing() Middleware {
+	tracer := otel.Tracer(instrumentationName)
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *message.Message) error {
+			d, _ := DeliveryFromContext(ctx)
+			ctx, span := tracer.Start(ctx, d.Topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.destination.name", d.Topic),
+					attribute.String("messaging.message.id", msg.UUID),
+					attribute.Int("events.attempt", d.Attempt),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// Metrics records the events.handler.attempts counter and the
+// events.handler.duration histogram, labelled by topic, consumer group and
+// outcome. Retries, failures and dead-lettering are recorded by the bus itself.
+func Metrics() (Middleware, error) {
+	meter := otel.Meter(instrumentationName)
+	attempts, err := meter.Int64Counter("events.handler.attempts",
+		metric.WithDescription("Event handler attempts."),
+		metric.WithUnit("{attempt}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("events: attempts counter: %w", err)
+	}
+	duration, err := meter.Float64Histogram("events.handler.duration",
+		metric.WithDescription("Duration of event handler attempts."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("events: duration histogram: %w", err)
+	}
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg *message.Message) error {
+			start := time.Now()
+			err := next(ctx, msg)
+
+			d, _ := DeliveryFromContext(ctx)
+			outcome := "success"
+			if err != nil {
+				outcome = "failure"
+			}
+			attrs := metric.WithAttributes(
+				attribute.String("topic", d.Topic),
+				attribute.String("consumer_group", d.ConsumerGroup),
+				attribute.String("outcome", outcome),
+			)
+			attempts.Add(ctx, 1, attrs)
+			duration.Record(ctx, time.Since(start).Seconds(), attrs)
+			return err
+		}
+	}, nil
+}
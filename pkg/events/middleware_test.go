@@ -0,0 +1,56 @@
+package events
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// TestChain_Order verifies the first middleware is the outermost.
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg *message.Message) error {
+				calls = append(calls, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+	handler := chain(func(_ context.Context, _ *message.Message) error {
+		calls = append(calls, "handler")
+		return nil
+	}, []Middleware{mw("outer"), mw("inner")})
+
+	if err := handler(context.Background(), message.NewMessage("id", nil)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"outer", "inner", "handler"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+}
+
+// TestRecoverer verifies a panic becomes an error.
+func TestRecoverer(t *testing.T) {
+	handler := Recoverer(nopLogger())(func(_ context.Context, _ *message.Message) error {
+		panic("boom")
+	})
+	if err := handler(context.Background(), message.NewMessage("id", nil)); err == nil {
+		t.Fatal("expected error from recovered panic")
+	}
+}
+
+// TestDeliveryFromContext verifies delivery details round-trip through the context.
+func TestDeliveryFromContext(t *testing.T) {
+	if _, ok := DeliveryFromContext(context.Background()); ok {
+		t.Error("expected no delivery in empty context")
+	}
+
+	ctx := contextWithDelivery(context.Background(), Delivery{Topic: "item.created", Handler: "h"})
+	got, ok := DeliveryFromContext(contextWithAttempt(ctx, 2))
+	if want := (Delivery{Topic: "item.created", Handler: "h", Attempt: 2}); !ok || got != want {
+		t.Errorf("got %+v (ok=%v), want %+v", got, ok, want)
+	}
+}
@@ -0,0 +1,364 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	watermillsql "github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// offsetsTablePrefix is the unquoted prefix of DefaultPostgreSQLOffsetsAdapter tables.
+const offsetsTablePrefix = "watermill_offsets_"
+
+// replayBatchSize is how many messages Replay reads per query.
+const replayBatchSize = 100
+
+// ErrOffsetNotFound is returned when resetting a consumer group to an offset
+// that does not exist on the topic.
+var ErrOffsetNotFound = errors.New("events: offset not found")
+
+// ConsumerGroupOffset is a consumer group's position on a topic.
+//
+// Watermill orders a topic by (transaction ID, offset), so Lag counts the
+// messages after the group's position rather than subtracting offsets.
+type ConsumerGroupOffset struct {
+	Topic         string `json:"topic"`
+	ConsumerGroup string `json:"consumer_group"`
+	Offset        int64  `json:"offset"` // last acknowledged message; 0 before the first
+	Lag           int64  `json:"lag"`    // messages not yet acknowledged
+}
+
+// offsetTables returns the quoted messages and offsets tables of topic.
+func offsetTables(topic string) (messages, offsets string, err error) {
+	if !topicNamePattern.MatchString(topic) {
+		return "", "", fmt.Errorf("%w %q", ErrInvalidTopic, topic)
+	}
+	return watermillsql.DefaultPostgreSQLSchema{}.MessagesTable(topic),
+		watermillsql.DefaultPostgreSQLOffsetsAdapter{}.MessagesOffsetsTable(topic), nil
+}
+
+// Topics returns every topic that has been subscribed to, in name order.
+func (q *EventBus) Topics(ctx context.Context) ([]string, error) {
+	if q.redis != nil {
+		return nil, ErrTransportUnsupported
+	}
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT table_name FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name LIKE $1
+		ORDER BY table_name`,
+		strings.ReplaceAll(offsetsTablePrefix, "_", `\_`)+"%",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("events: list topics: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var topics []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return nil, fmt.Errorf("events: list topics: %w", err)
+		}
+		topics = append(topics, strings.TrimPrefix(table, offsetsTablePrefix))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("events: list topics: %w", err)
+	}
+	return topics, nil
+}
+
+// ConsumerGroups returns the position and lag of every consumer group on
+// topic, or on all topics when topic is empty.
+func (q *EventBus) ConsumerGroups(ctx context.Context, topic string) ([]ConsumerGroupOffset, error) {
+	if q.redis != nil {
+		return nil, ErrTransportUnsupported
+	}
+	topics := []string{topic}
+	if topic == "" {
+		var err error
+		if topics, err = q.Topics(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var out []ConsumerGroupOffset
+	for _, topic := range topics {
+		groups, err := q.topicConsumerGroups(ctx, topic)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, groups...)
+	}
+	return out, nil
+}
+
+func (q *EventBus) topicConsumerGroups(ctx context.Context, topic string) ([]ConsumerGroupOffset, error) {
+	messages, offsets, err := offsetTables(topic)
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := q.tableExists(ctx, offsets); err != nil || !ok {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT o.consumer_group, o.offset_acked,
+			(SELECT count(*) FROM `+messages+` m
+			 WHERE (m.transaction_id, m."offset") > (o.last_processed_transaction_id, o.offset_acked))
+		FROM `+offsets+` o
+		ORDER BY o.consumer_group`)
+	if err != nil {
+		return nil, fmt.Errorf("events: list consumer groups of %s: %w", topic, err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ConsumerGroupOffset
+	for rows.Next() {
+		g := ConsumerGroupOffset{Topic: topic}
+		if err := rows.Scan(&g.ConsumerGroup, &g.Offset, &g.Lag); err != nil {
+			return nil, fmt.Errorf("events: list consumer groups of %s: %w", topic, err)
+		}
+		out = append(out, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("events: list consumer groups of %s: %w", topic, err)
+	}
+	return out, nil
+}
+
+// OffsetAt returns the offset of the last message published to topic before t,
+// or 0 if there is none.
+func (q *EventBus) OffsetAt(ctx context.Context, topic string, t time.Time) (int64, error) {
+	if q.redis != nil {
+		return 0, ErrTransportUnsupported
+	}
+	messages, _, err := offsetTables(topic)
+	if err != nil {
+		return 0, err
+	}
+	var offset int64
+	err = q.db.QueryRowContext(ctx, `
+		SELECT COALESCE((
+			SELECT "offset" FROM `+messages+`
+			WHERE created_at < $1
+			ORDER BY transaction_id DESC, "offset" DESC
+			LIMIT 1
+		), 0)`, t.UTC()).Scan(&offset)
+	if err != nil {
+		return 0, fmt.Errorf("events: find offset of %s at %s: %w", topic, t, err)
+	}
+	return offset, nil
+}
+
+// ResetOffset moves consumerGroup's position on topic so that it next
+// receives the messages after offset; 0 replays the whole topic. The partition
+// groups of a subscription with WithConcurrency move with their group; other
+// groups are not affected. Running subscribers of the group pick up the new
+// position on their next poll, so a message being handled during the reset
+// may be delivered once more. Returns ErrOffsetNotFound for an unknown offset.
+func (q *EventBus) ResetOffset(ctx context.Context, topic, consumerGroup string, offset int64) error {
+	if q.redis != nil {
+		return ErrTransportUnsupported
+	}
+	messages, offsets, err := offsetTables(topic)
+	if err != nil {
+		return err
+	}
+
+	// The position is (transaction ID, offset); '0' sorts before every transaction.
+	txID := "0"
+	if offset > 0 {
+		if txID, err = q.transactionOf(ctx, messages, topic, offset); err != nil {
+			return err
+		}
+	}
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("events: reset %s on %s: %w", consumerGroup, topic, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	groups, err := partitionGroupsOf(ctx, tx, offsets, consumerGroup)
+	if err != nil {
+		return fmt.Errorf("events: reset %s on %s: %w", consumerGroup, topic, err)
+	}
+	for _, group := range append([]string{consumerGroup}, groups...) {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+offsets+` (consumer_group, offset_acked, last_processed_transaction_id)
+			 VALUES ($1, $2, $3::xid8)
+			 ON CONFLICT (consumer_group) DO UPDATE
+			 SET offset_acked = excluded.offset_acked,
+			     last_processed_transaction_id = excluded.last_processed_transaction_id`,
+			group, offset, txID,
+		); err != nil {
+			return fmt.Errorf("events: reset %s on %s: %w", group, topic, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("events: reset %s on %s: %w", consumerGroup, topic, err)
+	}
+	q.log.InfoContext(ctx, "events: consumer group offset reset",
+		"topic", topic, "consumer_group", consumerGroup, "offset", offset,
+		"partition_groups", len(groups))
+	return nil
+}
+
+// transactionOf returns the transaction ID of the message at offset on topic.
+func (q *EventBus) transactionOf(ctx context.Context, messages, topic string, offset int64) (string, error) {
+	var txID string
+	err := q.db.QueryRowContext(ctx,
+		`SELECT transaction_id::text FROM `+messages+` WHERE "offset" = $1`, offset,
+	).Scan(&txID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %d on %s", ErrOffsetNotFound, offset, topic)
+	}
+	if err != nil {
+		return "", fmt.Errorf("events: find offset %d on %s: %w", offset, topic, err)
+	}
+	return txID, nil
+}
+
+// partitionGroupsOf returns the partition groups of group that have a
+// position in offsets, locking their rows until tx ends.
+func partitionGroupsOf(ctx context.Context, tx *sql.Tx, offsets, group string) ([]string, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT consumer_group FROM `+offsets+` WHERE starts_with(consumer_group, $1) FOR UPDATE`, group+"-p")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var groups []string
+	for rows.Next() {
+		var candidate string
+		if err := rows.Scan(&candidate); err != nil {
+			return nil, err
+		}
+		if isPartitionGroup(group, candidate) {
+			groups = append(groups, candidate)
+		}
+	}
+	return groups, rows.Err()
+}
+
+// Replay calls handler for each message on topic from offset from through
+// offset to, in delivery order; to <= 0 replays through the latest message.
+// Watermill delivers by (transaction ID, offset), so a message committed
+// between the two may have an offset outside [from, to]; both offsets must
+// exist on the topic, and from <= 1 starts at the first message. Messages are
+// read directly from the topic, so no consumer group's position changes and
+// other subscribers see nothing. Replay stops at the first handler error and
+// returns how many messages were handled before it.
+//
+// handler runs once per message without retries or dead-lettering, with the
+// publisher's trace restored and DeliveryFromContext reporting the topic and
+// name.
+func (q *EventBus) Replay(ctx context.Context, topic, name string, from, to int64, handler Handler) (int, error) {
+	if q.redis != nil {
+		return 0, ErrTransportUnsupported
+	}
+	messages, _, err := offsetTables(topic)
+	if err != nil {
+		return 0, err
+	}
+	// after is exclusive and last inclusive; a position at offset from - 1 in
+	// from's transaction comes right before from.
+	after, last := position{txID: "0"}, position{txID: maxTransactionID, offset: 1<<63 - 1}
+	if from > 1 {
+		if after.txID, err = q.transactionOf(ctx, messages, topic, from); err != nil {
+			return 0, err
+		}
+		after.offset = from - 1
+	}
+	if to > 0 {
+		if last.txID, err = q.transactionOf(ctx, messages, topic, to); err != nil {
+			return 0, err
+		}
+		last.offset = to
+	}
+	propagator := otel.GetTextMapPropagator()
+	handler = withCloudEvents(handler)
+
+	handled := 0
+	for {
+		batch, err := q.replayBatch(ctx, messages, after, last)
+		if err != nil {
+			return handled, fmt.Errorf("events: replay %s: %w", topic, err)
+		}
+		for _, rm := range batch {
+			carrier := propagation.MapCarrier{}
+			for k, v := range rm.msg.Metadata {
+				carrier[k] = v
+			}
+			msgCtx := contextWithDelivery(propagator.Extract(ctx, carrier),
+				Delivery{Topic: topic, Handler: name, Attempt: 1})
+			if err := handler(msgCtx, rm.msg); err != nil {
+				return handled, fmt.Errorf("events: replay %s offset %d: %w", topic, rm.offset, err)
+			}
+			handled++
+			after = rm.position
+		}
+		if len(batch) < replayBatchSize {
+			return handled, nil
+		}
+	}
+}
+
+// maxTransactionID is the largest xid8, which sorts after every transaction.
+const maxTransactionID = "18446744073709551615"
+
+// position is a message's place in Watermill's delivery order.
+type position struct {
+	txID   string
+	offset int64
+}
+
+// replayedMessage is a message read by Replay with its position.
+type replayedMessage struct {
+	position
+	msg *message.Message
+}
+
+// replayBatch reads the messages after position after through position last.
+func (q *EventBus) replayBatch(ctx context.Context, messages string, after, last position) ([]replayedMessage, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT transaction_id::text, "offset", uuid, payload, metadata FROM `+messages+`
+		WHERE (transaction_id, "offset") > ($1::xid8, $2)
+		  AND (transaction_id, "offset") <= ($3::xid8, $4)
+		ORDER BY transaction_id, "offset"
+		LIMIT $5`, after.txID, after.offset, last.txID, last.offset, replayBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var batch []replayedMessage
+	for rows.Next() {
+		var (
+			rm      replayedMessage
+			uuid    string
+			payload []byte
+			rawMeta []byte
+		)
+		if err := rows.Scan(&rm.txID, &rm.offset, &uuid, &payload, &rawMeta); err != nil {
+			return nil, err
+		}
+		rm.msg = message.NewMessage(uuid, payload)
+		if len(rawMeta) > 0 {
+			if err := json.Unmarshal(rawMeta, &rm.msg.Metadata); err != nil {
+				return nil, fmt.Errorf("unmarshal metadata of offset %d: %w", rm.offset, err)
+			}
+		}
+		batch = append(batch, rm)
+	}
+	return batch, rows.Err()
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func TestOffsetTables(t *testing.T) {
+	messages, offsets, err := offsetTables("item.created")
+	if err != nil {
+		t.Fatalf("offsetTables: %v", err)
+	}
+	if messages != `"watermill_item.created"` || offsets != `"watermill_offsets_item.created"` {
+		t.Errorf("tables = %s, %s", messages, offsets)
+	}
+
+	if _, _, err := offsetTables(`item"; DROP TABLE x; --`); !errors.Is(err, ErrInvalidTopic) {
+		t.Errorf("err = %v, want ErrInvalidTopic", err)
+	}
+}
+
+// TestResetOffset_MovesPartitionGroups verifies the partition groups of a
+// WithConcurrency subscription move with their group and others stay put.
+func TestResetOffset_MovesPartitionGroups(t *testing.T) {
+	q, db := newFakeBus(t)
+	db.tables.messages = []fakeMessage{{topic: "item.created", position: position{txID: "7", offset: 3}, uuid: "m3"}}
+	for _, group := range []string{"svc-consumer-p0of2", "svc-consumer-p1of2", "svc-consumer-audit", "other-consumer"} {
+		db.tables.offsets["item.created/"+group] = position{txID: "9", offset: 5}
+	}
+
+	if err := q.ResetOffset(context.Background(), "item.created", "svc-consumer", 3); err != nil {
+		t.Fatalf("ResetOffset: %v", err)
+	}
+	got := db.snapshot().offsets
+	for group, want := range map[string]position{
+		"svc-consumer":       {txID: "7", offset: 3},
+		"svc-consumer-p0of2": {txID: "7", offset: 3},
+		"svc-consumer-p1of2": {txID: "7", offset: 3},
+		"svc-consumer-audit": {txID: "9", offset: 5},
+		"other-consumer":     {txID: "9", offset: 5},
+	} {
+		if got["item.created/"+group] != want {
+			t.Errorf("%s = %+v, want %+v", group, got["item.created/"+group], want)
+		}
+	}
+
+	if err := q.ResetOffset(context.Background(), "item.created", "svc-consumer", 4); !errors.Is(err, ErrOffsetNotFound) {
+		t.Errorf("unknown offset: err = %v, want ErrOffsetNotFound", err)
+	}
+}
+
+// TestReplay_DeliveryOrder verifies Replay follows (transaction ID, offset)
+// across batches, so a message whose offset was assigned before an earlier
+// commit is neither skipped nor replayed out of order.
+func TestReplay_DeliveryOrder(t *testing.T) {
+	q, db := newFakeBus(t)
+	// Offsets 1..n commit in order, except offset 2 commits last.
+	n := int64(replayBatchSize + 5)
+	for offset := int64(1); offset <= n; offset++ {
+		txID := strconv.FormatInt(100+offset, 10)
+		if offset == 2 {
+			txID = "1000"
+		}
+		db.tables.messages = append(db.tables.messages, fakeMessage{
+			topic: "item.created", position: position{txID: txID, offset: offset}, uuid: fmt.Sprint("m", offset),
+		})
+	}
+
+	replay := func(from, to int64) []string {
+		t.Helper()
+		var got []string
+		_, err := q.Replay(context.Background(), "item.created", "h", from, to, func(_ context.Context, msg *message.Message) error {
+			got = append(got, msg.UUID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Replay(%d, %d): %v", from, to, err)
+		}
+		return got
+	}
+
+	all := replay(0, 0)
+	if len(all) != int(n) || all[0] != "m1" || all[1] != "m3" || all[n-1] != "m2" {
+		t.Errorf("all: got %d messages, first %v, last %s", len(all), all[:2], all[len(all)-1])
+	}
+	if got := replay(3, 5); !slices.Equal(got, []string{"m3", "m4", "m5"}) {
+		t.Errorf("[3, 5] = %v", got)
+	}
+	if got := replay(n, 2); !slices.Equal(got, []string{fmt.Sprint("m", n), "m2"}) {
+		t.Errorf("[%d, 2] = %v", n, got)
+	}
+	if _, err := q.Replay(context.Background(), "item.created", "h", 1, n+1, nil); !errors.Is(err, ErrOffsetNotFound) {
+		t.Errorf("unknown offset: err = %v, want ErrOffsetNotFound", err)
+	}
+}
@@ -0,0 +1,104 @@
+package events
+
+import "time"
+
+// SubscribeOption configures a single Subscribe call.
+type SubscribeOption func(*subscribeConfig)
+
+type subscribeConfig struct {
+	inbox         bool
+	broadcast     bool
+	consumerGroup string // overrides the bus's default consumer group
+	handlerName   string // overrides the reflected handler name in DLQ metadata
+	retry         RetryPolicy
+	timeout       time.Duration // per-attempt handler deadline
+	middleware    []Middleware
+	concurrency   int // parallel partitions; <= 1 processes messages one at a time
+}
+
+func newSubscribeConfig(opts []SubscribeOption) subscribeConfig {
+	var cfg subscribeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.retry = cfg.retry.withDefaults()
+	return cfg
+}
+
+// WithInbox makes the subscription idempotent using a transactional inbox.
+//
+// Each delivery runs in a database transaction that first records
+// (consumer group, event_id) in the inbox table. If the row already exists the
+// event was processed before: the message is Acked and the handler is skipped.
+// Otherwise the handler runs and the transaction commits only if it succeeds,
+// so a failed attempt leaves no inbox row and is retried normally.
+//
+// Handlers should perform their database writes through TxFromContext so the
+// side effects commit atomically with the inbox row. Side effects outside the
+// database (Redis, HTTP calls) are only protected against redelivery after a
+// successful commit.
+func WithInbox() SubscribeOption {
+	return func(c *subscribeConfig) { c.inbox = true }
+}
+
+// WithConsumerGroup load-balances the subscription across all subscribers that
+// use the same group name instead of the bus's default <service>-consumer group.
+// Use it to let a second, independent handler consume a topic the default group
+// already consumes.
+func WithConsumerGroup(name string) SubscribeOption {
+	return func(c *subscribeConfig) { c.consumerGroup = name }
+}
+
+// WithBroadcast delivers every message on the topic to this subscription,
+// regardless of how many other instances subscribe to it. Use it for signals
+// every instance must see, such as cache invalidation or configuration reload.
+//
+// Each broadcast subscription gets its own consumer group and starts at the end
+// of the topic: messages published before Subscribe are not delivered, and
+// messages published while the instance is down are missed. The group is
+// deleted when the subscription ends. WithBroadcast takes precedence over
+// WithConsumerGroup.
+func WithBroadcast() SubscribeOption {
+	return func(c *subscribeConfig) { c.broadcast = true }
+}
+
+// WithRetryPolicy replaces the default retry behavior (3 attempts, 1s then 2s
+// apart) for the subscription. Zero fields keep their defaults.
+func WithRetryPolicy(p RetryPolicy) SubscribeOption {
+	return func(c *subscribeConfig) { c.retry = p }
+}
+
+// WithAttemptTimeout cancels the context of a handler call that runs longer
+// than d. The timed-out attempt fails with context.DeadlineExceeded and is
+// retried like any other failure.
+func WithAttemptTimeout(d time.Duration) SubscribeOption {
+	return func(c *subscribeConfig) { c.timeout = d }
+}
+
+// WithMiddleware wraps the handler with mws, the first being the outermost.
+// Repeated calls append to the chain. See DefaultMiddleware for the standard stack.
+func WithMiddleware(mws ...Middleware) SubscribeOption {
+	return func(c *subscribeConfig) { c.middleware = append(c.middleware, mws...) }
+}
+
+// WithConcurrency processes up to n messages of the subscription in parallel.
+// Messages with the same partition key (by default the aggregate ID, see
+// MetaPartitionKey) are still processed one at a time, in publish order.
+//
+// The subscription is split into n partitions by a hash of the key. Each
+// partition reads the whole topic and skips messages of other partitions; on
+// EventBus it does so in its own consumer group (<group>-p<i>of<n>), seeded
+// from the unpartitioned group's offset on first use. Changing n therefore
+// redelivers messages handled since the last change; combine it with WithInbox
+// to skip them. A dead-lettered message no longer holds back later messages
+// with its key.
+func WithConcurrency(n int) SubscribeOption {
+	return func(c *subscribeConfig) { c.concurrency = n }
+}
+
+// WithHandlerName names the handler in dead-letter metadata, logs, metrics and
+// Replay. Without it the name is the handler's Go function name, which is
+// unstable for closures.
+func WithHandlerName(name string) SubscribeOption {
+	return func(c *subscribeConfig) { c.handlerName = name }
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// MetaPartitionKey orders delivery: messages with the same key are processed
+// one at a time, in publish order, even by a subscription with WithConcurrency.
+const MetaPartitionKey = "partition_key"
+
+// AggregateEvent is implemented by events that belong to an aggregate.
+// NewTypedMessage uses the aggregate ID as the default partition key, so all
+// events of one aggregate are processed in the order they were published.
+type AggregateEvent interface {
+	GetAggregateID() uuid.UUID
+}
+
+// PublishOption configures a single typed publish.
+type PublishOption func(*publishConfig)
+
+type publishConfig struct {
+	partitionKey string
+}
+
+// WithPartitionKey overrides the event's default partition key (its aggregate ID).
+func WithPartitionKey(key string) PublishOption {
+	return func(c *publishConfig) { c.partitionKey = key }
+}
+
+// SetPartitionKey sets msg's partition key. Use it for messages built without
+// NewTypedMessage and published through Bus.Publish.
+func SetPartitionKey(msg *message.Message, key string) {
+	msg.Metadata.Set(MetaPartitionKey, key)
+}
+
+// PartitionKeyOf returns msg's partition key. Messages without one fall back
+// to their event ID, which spreads them across partitions with no ordering.
+func PartitionKeyOf(msg *message.Message) string {
+	if key := msg.Metadata.Get(MetaPartitionKey); key != "" {
+		return key
+	}
+	return eventIDOf(msg)
+}
+
+// partition selects the share of a subscription's messages one delivery loop
+// processes. Each partition reads the whole topic and Acks the messages of
+// other partitions without handling them.
+type partition struct {
+	index int
+	count int
+}
+
+// owns reports whether msg's partition key hashes to p.
+func (p partition) owns(msg *message.Message) bool {
+	if p.count <= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(PartitionKeyOf(msg)))
+	return int(h.Sum32()%uint32(p.count)) == p.index //nolint:gosec // count is a small positive int
+}
+
+// partitions returns the partitions of a subscription with the given concurrency.
+func partitions(concurrency int) []partition {
+	count := max(concurrency, 1)
+	parts := make([]partition, count)
+	for i := range parts {
+		parts[i] = partition{index: i, count: count}
+	}
+	return parts
+}
+
+// subscribePartitioned opens one transport subscription per partition of cfg
+// with open and runs a delivery loop for each through deliverTo. Messages with
+// the same partition key land in the same loop and are processed sequentially;
+// loops run in parallel. Their errors are merged into the returned channel.
+func subscribePartitioned(
+	ctx context.Context,
+	log logger.Logger,
+	topic string,
+	cfg subscribeConfig,
+	open func(ctx context.Context, part partition) (<-chan *message.Message, error),
+	deliverTo func(ctx context.Context, msgs <-chan *message.Message, part partition) <-chan error,
+) (<-chan error, error) {
+	parts := partitions(cfg.concurrency)
+	if len(parts) == 1 {
+		msgs, err := open(ctx, parts[0])
+		if err != nil {
+			return nil, err
+		}
+		return deliverTo(ctx, msgs, parts[0]), nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	chans := make([]<-chan *message.Message, len(parts))
+	for i, part := range parts {
+		msgs, err := open(ctx, part)
+		if err != nil {
+			cancel() // stop the partitions opened so far
+			return nil, err
+		}
+		chans[i] = msgs
+	}
+
+	out := make(chan error, 100)
+	var merge sync.WaitGroup
+	for i, part := range parts {
+		errCh := deliverTo(ctx, chans[i], part)
+		merge.Add(1)
+		go func() {
+			defer merge.Done()
+			for err := range errCh {
+				select {
+				case out <- err:
+				default:
+					log.ErrorContext(ctx, "events: error channel full, dropping error",
+						"error", err, "topic", topic)
+				}
+			}
+		}()
+	}
+	go func() {
+		merge.Wait()
+		cancel()
+		close(out)
+	}()
+	return out, nil
+}
+
+// partitionGroup returns the consumer group of part within group. A
+// subscription without WithConcurrency keeps group unchanged.
+func partitionGroup(group string, part partition) string {
+	if part.count <= 1 {
+		return group
+	}
+	return fmt.Sprintf("%s-p%dof%d", group, part.index, part.count)
+}
+
+// isPartitionGroup reports whether candidate is a partition group of group,
+// as named by partitionGroup.
+func isPartitionGroup(group, candidate string) bool {
+	suffix, ok := strings.CutPrefix(candidate, group)
+	if !ok {
+		return false
+	}
+	var index, count int
+	n, err := fmt.Sscanf(suffix, "-p%dof%d", &index, &count)
+	return err == nil && n == 2 && index >= 0 && index < count &&
+		suffix == fmt.Sprintf("-p%dof%d", index, count)
+}
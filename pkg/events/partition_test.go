@@ -0,0 +1,173 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+)
+
+type testAggregateEvent struct {
+	ItemID uuid.UUID `json:"item_id"`
+}
+
+func (e testAggregateEvent) GetAggregateID() uuid.UUID { return e.ItemID }
+
+func init() {
+	Register[testAggregateEvent]("test.aggregate", 1)
+}
+
+// TestNewTypedMessage_PartitionKey verifies the aggregate ID default and its override.
+func TestNewTypedMessage_PartitionKey(t *testing.T) {
+	evt := testAggregateEvent{ItemID: uuid.New()}
+
+	_, msg, err := NewTypedMessage(context.Background(), evt)
+	if err != nil {
+		t.Fatalf("NewTypedMessage: %v", err)
+	}
+	if got := PartitionKeyOf(msg); got != evt.ItemID.String() {
+		t.Errorf("default: got %q, want %q", got, evt.ItemID)
+	}
+
+	_, msg, err = NewTypedMessage(context.Background(), evt, WithPartitionKey("org-1"))
+	if err != nil {
+		t.Fatalf("NewTypedMessage: %v", err)
+	}
+	if got := PartitionKeyOf(msg); got != "org-1" {
+		t.Errorf("override: got %q, want %q", got, "org-1")
+	}
+}
+
+// TestPartitionKeyOf_Fallback verifies messages without a key fall back to their event ID.
+func TestPartitionKeyOf_Fallback(t *testing.T) {
+	msg := message.NewMessage("msg-uuid", nil)
+	if got := PartitionKeyOf(msg); got != "msg-uuid" {
+		t.Errorf("got %q, want %q", got, "msg-uuid")
+	}
+}
+
+// TestPartition_Owns verifies every key is owned by exactly one partition.
+func TestPartition_Owns(t *testing.T) {
+	parts := partitions(4)
+	for i := range 100 {
+		msg := message.NewMessage("id", nil)
+		SetPartitionKey(msg, fmt.Sprintf("key-%d", i))
+
+		owners := 0
+		for _, p := range parts {
+			if p.owns(msg) {
+				owners++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("key-%d: owned by %d partitions, want 1", i, owners)
+		}
+	}
+
+	if got := len(partitions(0)); got != 1 {
+		t.Errorf("partitions(0): got %d, want 1", got)
+	}
+}
+
+// TestPartitionGroup verifies unpartitioned subscriptions keep their group.
+func TestPartitionGroup(t *testing.T) {
+	if got := partitionGroup("svc-consumer", partition{index: 0, count: 1}); got != "svc-consumer" {
+		t.Errorf("single: got %q, want %q", got, "svc-consumer")
+	}
+	if got := partitionGroup("svc-consumer", partition{index: 2, count: 4}); got != "svc-consumer-p2of4" {
+		t.Errorf("partitioned: got %q, want %q", got, "svc-consumer-p2of4")
+	}
+}
+
+// TestIsPartitionGroup verifies only partitionGroup names of the group match.
+func TestIsPartitionGroup(t *testing.T) {
+	for candidate, want := range map[string]bool{
+		"svc-consumer-p0of4":   true,
+		"svc-consumer-p3of4":   true,
+		"svc-consumer":         false,
+		"svc-consumer-p4of4":   false,
+		"svc-consumer-p1of4x":  false,
+		"svc-consumer-x-p1of4": false,
+		"other-consumer-p1of4": false,
+	} {
+		if got := isPartitionGroup("svc-consumer", candidate); got != want {
+			t.Errorf("isPartitionGroup(%q) = %v, want %v", candidate, got, want)
+		}
+	}
+}
+
+// TestMemoryBus_ConcurrencyKeepsKeyOrder verifies messages with the same key
+// are handled sequentially and in order while keys are spread over partitions.
+func TestMemoryBus_ConcurrencyKeepsKeyOrder(t *testing.T) {
+	bus := NewMemoryBus(nopLogger())
+	defer bus.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const keys, perKey = 4, 10
+	var (
+		mu     sync.Mutex
+		seen   = map[string][]string{}
+		active = map[string]bool{}
+		done   = make(chan struct{})
+		total  int
+	)
+	errCh, err := bus.Subscribe(ctx, "test.ordered", func(_ context.Context, msg *message.Message) error {
+		key := PartitionKeyOf(msg)
+		mu.Lock()
+		if active[key] {
+			t.Errorf("key %s handled concurrently", key)
+		}
+		active[key] = true
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		active[key] = false
+		seen[key] = append(seen[key], string(msg.Payload))
+		if total++; total == keys*perKey {
+			close(done)
+		}
+		return nil
+	}, WithConcurrency(3))
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	go func() {
+		for range errCh {
+		}
+	}()
+
+	for i := range perKey {
+		for k := range keys {
+			msg := message.NewMessage(uuid.NewString(), []byte(fmt.Sprint(i)))
+			SetPartitionKey(msg, fmt.Sprintf("key-%d", k))
+			if err := bus.Publish(ctx, "test.ordered", msg); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for delivery")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for key, got := range seen {
+		for i, payload := range got {
+			if payload != fmt.Sprint(i) {
+				t.Fatalf("key %s: got order %v", key, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,463 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// Transports selectable with cfg.EventTransport.
+const (
+	TransportPostgres = "postgres" // Watermill SQL tables in the definition database
+	TransportRedis    = "redis"    // Redis Streams at cfg.RedisURL
+)
+
+const (
+	redisStreamPrefix = "events:" // stream key of a topic: events:<topic>
+	redisReadCount    = 10        // entries read or claimed per round trip
+	redisBlock        = time.Second
+	redisNackDelay    = time.Second // wait before redelivering a Nacked entry
+	redisRetryDelay   = time.Second // wait after a failed read
+	redisOpTimeout    = 5 * time.Second
+	redisClaimIdle    = time.Minute // default when cfg.EventRedisClaimIdle is unset
+)
+
+// ErrTransportUnsupported is returned by operations that need the Watermill
+// SQL tables, such as offset management and Replay, on a bus using another transport.
+var ErrTransportUnsupported = errors.New("events: operation not supported by the event transport")
+
+// redisStreams carries messages over Redis Streams: each topic is a stream
+// and each consumer group a Redis consumer group on it, so XREADGROUP hands
+// every entry to one consumer of the group. Entries stay pending until their
+// message is Acked (XACK); entries left pending longer than claimIdle by a
+// crashed or stuck consumer are taken over with XCLAIM.
+type redisStreams struct {
+	client    *redis.Client
+	log       logger.Logger
+	consumer  string        // this process's consumer name in every group
+	maxLen    int64         // approximate stream length cap (XADD MAXLEN ~); 0 keeps every entry
+	claimIdle time.Duration // pending entries idle this long are claimed from their consumer
+	block     time.Duration // XREADGROUP BLOCK
+	nackDelay time.Duration
+
+	closing   chan struct{} // closed by stop: no new reads
+	closeOnce sync.Once
+}
+
+// newRedisStreams connects to cfg.RedisURL.
+func newRedisStreams(cfg *config.Config, log logger.Logger) (*redisStreams, error) {
+	opts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		return nil, fmt.Errorf("events: parse redis url: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("events: ping redis: %w", err)
+	}
+	return newRedisStreamsFrom(client, log, cfg.EventRedisMaxLen, cfg.EventRedisClaimIdle), nil
+}
+
+func newRedisStreamsFrom(client *redis.Client, log logger.Logger, maxLen int64, claimIdle time.Duration) *redisStreams {
+	if claimIdle <= 0 {
+		claimIdle = redisClaimIdle
+	}
+	host, _ := os.Hostname()
+	return &redisStreams{
+		client:    client,
+		log:       log,
+		consumer:  host + "-" + watermill.NewShortUUID(),
+		maxLen:    maxLen,
+		claimIdle: claimIdle,
+		block:     redisBlock,
+		nackDelay: redisNackDelay,
+		closing:   make(chan struct{}),
+	}
+}
+
+// streamKey returns the stream backing topic.
+func streamKey(topic string) string {
+	return redisStreamPrefix + topic
+}
+
+// Publish appends msgs to topic's stream in one MULTI/EXEC transaction, so a
+// batch is published entirely or not at all.
+func (r *redisStreams) Publish(topic string, msgs ...*message.Message) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	stream := streamKey(topic)
+	_, err := r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		for _, msg := range msgs {
+			metadata, err := json.Marshal(msg.Metadata)
+			if err != nil {
+				return fmt.Errorf("events: marshal metadata for %s: %w", msg.UUID, err)
+			}
+			p.XAdd(ctx, &redis.XAddArgs{
+				Stream: stream,
+				MaxLen: r.maxLen,
+				Approx: true,
+				Values: []any{"uuid", msg.UUID, "payload", []byte(msg.Payload), "metadata", metadata},
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("events: xadd %s: %w", stream, err)
+	}
+	return nil
+}
+
+// Close stops all subscriptions and closes the Redis connection.
+func (r *redisStreams) Close() error {
+	r.stop()
+	return r.client.Close()
+}
+
+// stop makes subscriptions exit once their in-flight message is Acked or Nacked.
+func (r *redisStreams) stop() {
+	r.closeOnce.Do(func() { close(r.closing) })
+}
+
+// stopped reports whether the subscription under ctx must exit.
+func (r *redisStreams) stopped(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-r.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// subscribe joins group on topic's stream, creating the group at start (a
+// stream ID, "0" for the whole stream or "$" for new entries only) if it does
+// not exist, and sends its messages on the returned channel until ctx is
+// cancelled or the transport stops. Each message is sent only after the
+// previous one was Acked or Nacked, so the group sees the topic in order.
+// With destroy the group is deleted on exit.
+func (r *redisStreams) subscribe(
+	ctx context.Context,
+	wg *sync.WaitGroup,
+	topic, group, start string,
+	destroy bool,
+) (<-chan *message.Message, error) {
+	stream := streamKey(topic)
+	if err := r.createGroup(ctx, stream, group, start); err != nil {
+		return nil, err
+	}
+
+	out := make(chan *message.Message)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(out)
+		if destroy {
+			defer r.destroyGroup(stream, group)
+		}
+		r.consume(ctx, stream, group, out)
+	}()
+	return out, nil
+}
+
+// createGroup creates group on stream, and the stream if needed. An existing
+// group keeps its position.
+func (r *redisStreams) createGroup(ctx context.Context, stream, group, start string) error {
+	err := r.client.XGroupCreateMkStream(ctx, stream, group, start).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("events: create group %s on %s: %w", group, stream, err)
+	}
+	return nil
+}
+
+func (r *redisStreams) destroyGroup(stream, group string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := r.client.XGroupDestroy(ctx, stream, group).Err(); err != nil {
+		r.log.Warn("events: destroy redis consumer group", "stream", stream, "group", group, "error", err)
+	}
+}
+
+// consume claims stale entries of group and reads new ones until the
+// subscription stops. Failed reads are logged and retried.
+func (r *redisStreams) consume(ctx context.Context, stream, group string, out chan<- *message.Message) {
+	for !r.stopped(ctx) {
+		entries, err := r.claim(ctx, stream, group)
+		if err == nil && len(entries) == 0 {
+			entries, err = r.read(ctx, stream, group)
+		}
+		if err != nil {
+			if r.stopped(ctx) {
+				return
+			}
+			r.log.ErrorContext(ctx, "events: read redis stream failed",
+				"stream", stream, "group", group, "retry_in", redisRetryDelay, "error", err)
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// The stream or group was deleted; recreate it and read what was added since.
+				_ = r.createGroup(ctx, stream, group, "0")
+			}
+			select {
+			case <-ctx.Done():
+			case <-r.closing:
+			case <-time.After(redisRetryDelay):
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			if !r.send(ctx, stream, group, entry, out) {
+				return
+			}
+		}
+	}
+}
+
+// claim takes over up to redisReadCount entries of group that have been
+// pending for at least claimIdle, e.g. because their consumer crashed.
+func (r *redisStreams) claim(ctx context.Context, stream, group string) ([]redis.XMessage, error) {
+	pending, err := r.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Idle:   r.claimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  redisReadCount,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	ids := make([]string, len(pending))
+	for i, p := range pending {
+		ids[i] = p.ID
+	}
+	entries, err := r.client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: r.consumer,
+		MinIdle:  r.claimIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > 0 {
+		r.log.WarnContext(ctx, "events: claimed stale redis stream entries",
+			"stream", stream, "group", group, "count", len(entries))
+	}
+	return entries, nil
+}
+
+// read returns up to redisReadCount entries never delivered to group, waiting
+// up to block for one to arrive.
+func (r *redisStreams) read(ctx context.Context, stream, group string) ([]redis.XMessage, error) {
+	streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: r.consumer,
+		Streams:  []string{stream, ">"},
+		Count:    redisReadCount,
+		Block:    r.block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []redis.XMessage
+	for _, s := range streams {
+		entries = append(entries, s.Messages...)
+	}
+	return entries, nil
+}
+
+// send delivers entry on out and waits for its message to be Acked, which
+// acknowledges the entry, or Nacked, which sends it again after nackDelay.
+// While the handler runs the entry's idle time is reset, so other consumers
+// do not claim it. Returns false when the subscription stops first; the entry
+// then stays pending until another consumer claims it.
+func (r *redisStreams) send(ctx context.Context, stream, group string, entry redis.XMessage, out chan<- *message.Message) bool {
+	msg, err := decodeStreamEntry(entry)
+	if err != nil {
+		r.log.ErrorContext(ctx, "events: dropping malformed redis stream entry",
+			"stream", stream, "id", entry.ID, "error", err)
+		r.ack(stream, group, entry.ID)
+		return true
+	}
+
+	heartbeat := time.NewTicker(max(r.claimIdle/2, 10*time.Millisecond))
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case out <- msg:
+		case <-ctx.Done():
+			return false
+		case <-r.closing:
+			return false
+		}
+
+	wait:
+		for {
+			select {
+			case <-msg.Acked():
+				r.ack(stream, group, entry.ID)
+				return true
+			case <-msg.Nacked():
+				break wait
+			case <-heartbeat.C:
+				r.touch(ctx, stream, group, entry.ID)
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		select {
+		case <-time.After(r.nackDelay):
+		case <-ctx.Done():
+			return false
+		case <-r.closing:
+			return false
+		}
+		msg = msg.Copy()
+	}
+}
+
+// ack acknowledges id in group. It outlives the subscription's context, so a
+// message Acked during shutdown is not redelivered.
+func (r *redisStreams) ack(stream, group, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+	if err := r.client.XAck(ctx, stream, group, id).Err(); err != nil {
+		r.log.ErrorContext(ctx, "events: xack failed, entry will be redelivered",
+			"stream", stream, "group", group, "id", id, "error", err)
+	}
+}
+
+// touch resets the idle time of id, which is still being handled.
+func (r *redisStreams) touch(ctx context.Context, stream, group, id string) {
+	err := r.client.XClaimJustID(ctx, &redis.XClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: r.consumer,
+		Messages: []string{id},
+	}).Err()
+	if err != nil && ctx.Err() == nil {
+		r.log.WarnContext(ctx, "events: refresh pending redis stream entry",
+			"stream", stream, "id", id, "error", err)
+	}
+}
+
+// groupStart returns the position at which a new group should start to
+// continue where group stopped on topic: before its oldest pending entry, or
+// after its last delivered one. A missing group or stream starts at "0".
+func (r *redisStreams) groupStart(ctx context.Context, topic, group string) (string, error) {
+	stream := streamKey(topic)
+	groups, err := r.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return "0", nil
+		}
+		return "", fmt.Errorf("events: groups of %s: %w", stream, err)
+	}
+	for _, g := range groups {
+		if g.Name != group {
+			continue
+		}
+		if g.Pending == 0 {
+			return g.LastDeliveredID, nil
+		}
+		pending, err := r.client.XPending(ctx, stream, group).Result()
+		if err != nil {
+			return "", fmt.Errorf("events: pending entries of %s on %s: %w", group, stream, err)
+		}
+		return previousStreamID(pending.Lower)
+	}
+	return "0", nil
+}
+
+// previousStreamID returns the ID immediately before id ("<ms>-<seq>").
+func previousStreamID(id string) (string, error) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	ms, err1 := strconv.ParseUint(msPart, 10, 64)
+	seq, err2 := strconv.ParseUint(seqPart, 10, 64)
+	if !ok || err1 != nil || err2 != nil {
+		return "", fmt.Errorf("events: invalid stream id %q", id)
+	}
+	switch {
+	case seq > 0:
+		return fmt.Sprintf("%d-%d", ms, seq-1), nil
+	case ms > 0:
+		return fmt.Sprintf("%d-%d", ms-1, uint64(math.MaxUint64)), nil
+	default:
+		return "0", nil
+	}
+}
+
+// decodeStreamEntry converts a stream entry written by Publish to a message.
+func decodeStreamEntry(entry redis.XMessage) (*message.Message, error) {
+	field := func(name string) (string, error) {
+		v, ok := entry.Values[name].(string)
+		if !ok {
+			return "", fmt.Errorf("missing field %q", name)
+		}
+		return v, nil
+	}
+	uuid, err := field("uuid")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := field("payload")
+	if err != nil {
+		return nil, err
+	}
+	msg := message.NewMessage(uuid, []byte(payload))
+	if raw, err := field("metadata"); err == nil {
+		if err := json.Unmarshal([]byte(raw), &msg.Metadata); err != nil {
+			return nil, fmt.Errorf("unmarshal metadata: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+// subscribeRedis subscribes to topic in part's consumer group within group on
+// the Redis transport. As on Postgres, a new broadcast group starts at the end
+// of the topic and a new partition group where group stopped; a broadcast
+// group is deleted when its subscription ends.
+func (q *EventBus) subscribeRedis(
+	ctx context.Context,
+	topic, group string,
+	part partition,
+	broadcast bool,
+) (<-chan *message.Message, error) {
+	partGroup := partitionGroup(group, part)
+	start := "0"
+	switch {
+	case broadcast:
+		start = "$"
+	case partGroup != group:
+		var err error
+		if start, err = q.redis.groupStart(ctx, topic, group); err != nil {
+			return nil, err
+		}
+	}
+	return q.redis.subscribe(ctx, &q.wg, topic, partGroup, start, broadcast)
+}
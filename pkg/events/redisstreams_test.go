@@ -0,0 +1,348 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// capturePublisher records published messages by topic.
+type capturePublisher struct {
+	mu   sync.Mutex
+	msgs map[string][]*message.Message
+}
+
+func (p *capturePublisher) Publish(topic string, msgs ...*message.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.msgs == nil {
+		p.msgs = map[string][]*message.Message{}
+	}
+	p.msgs[topic] = append(p.msgs[topic], msgs...)
+	return nil
+}
+
+func (p *capturePublisher) Close() error { return nil }
+
+func (p *capturePublisher) count(topic string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.msgs[topic])
+}
+
+// newTestRedisStreams returns a transport on a fresh miniredis server, with
+// short timings so tests and shutdown run quickly.
+func newTestRedisStreams(t *testing.T) *redisStreams {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	r := newRedisStreamsFrom(redis.NewClient(&redis.Options{Addr: mr.Addr()}), nopLogger(), 0, 50*time.Millisecond)
+	r.block = 20 * time.Millisecond
+	r.nackDelay = 10 * time.Millisecond
+	return r
+}
+
+// newTestRedisBus returns an EventBus on the Redis transport without a
+// database: subscriptions must not use WithInbox. Dead letters go to the
+// returned publisher.
+func newTestRedisBus(t *testing.T) (*EventBus, *capturePublisher) {
+	t.Helper()
+	r := newTestRedisStreams(t)
+	dlq := &capturePublisher{}
+	bus := &EventBus{
+		publisher:     meteredPublisher{next: r, metrics: nopBusMetrics()},
+		deadLetters:   dlq,
+		redis:         r,
+		log:           nopLogger(),
+		serviceName:   "test",
+		consumerGroup: "test-consumer",
+		metrics:       nopBusMetrics(),
+	}
+	t.Cleanup(func() {
+		r.stop()
+		bus.wg.Wait()
+		_ = r.client.Close()
+	})
+	return bus, dlq
+}
+
+// drain discards errCh's errors.
+func drain(errCh <-chan error) {
+	go func() {
+		for range errCh {
+		}
+	}()
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// pendingCount returns how many entries of group on topic are unacknowledged.
+func pendingCount(t *testing.T, r *redisStreams, topic, group string) int64 {
+	t.Helper()
+	p, err := r.client.XPending(context.Background(), streamKey(topic), group).Result()
+	if err != nil {
+		t.Fatalf("xpending: %v", err)
+	}
+	return p.Count
+}
+
+// receive returns the next message on msgs.
+func receive(t *testing.T, msgs <-chan *message.Message) *message.Message {
+	t.Helper()
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a message")
+		return nil
+	}
+}
+
+// TestRedisTransport_PublishSubscribe verifies messages arrive in publish
+// order with their metadata and are acknowledged once handled.
+func TestRedisTransport_PublishSubscribe(t *testing.T) {
+	bus, _ := newTestRedisBus(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan *message.Message, 3)
+	errCh, err := bus.Subscribe(ctx, "test.redis", func(_ context.Context, msg *message.Message) error {
+		got <- msg
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	drain(errCh)
+
+	var msgs []*message.Message
+	for _, id := range []string{"a", "b", "c"} {
+		msg := message.NewMessage(id, []byte(`{"id":"`+id+`"}`))
+		msg.Metadata.Set(MetaEventID, "evt-"+id)
+		msgs = append(msgs, msg)
+	}
+	if err := bus.Publish(ctx, "test.redis", msgs...); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	for _, want := range msgs {
+		msg := receive(t, got)
+		if msg.UUID != want.UUID || string(msg.Payload) != string(want.Payload) {
+			t.Errorf("got %s %s, want %s %s", msg.UUID, msg.Payload, want.UUID, want.Payload)
+		}
+		if msg.Metadata.Get(MetaEventID) != "evt-"+want.UUID {
+			t.Errorf("%s: event_id %q", msg.UUID, msg.Metadata.Get(MetaEventID))
+		}
+	}
+	waitFor(t, "acknowledgements", func() bool {
+		return pendingCount(t, bus.redis, "test.redis", "test-consumer") == 0
+	})
+}
+
+// TestRedisTransport_DeadLetter verifies a permanently failing message is
+// dead-lettered and acknowledged, so it is not redelivered.
+func TestRedisTransport_DeadLetter(t *testing.T) {
+	bus, dlq := newTestRedisBus(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh, err := bus.Subscribe(ctx, "test.redis", func(context.Context, *message.Message) error {
+		return Permanent(errors.New("malformed"))
+	})
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	drain(errCh)
+
+	if err := bus.Publish(ctx, "test.redis", message.NewMessage("poison", []byte("{}"))); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	waitFor(t, "dead letter", func() bool { return dlq.count(DeadLetterTopic("test.redis")) == 1 })
+	waitFor(t, "acknowledgement", func() bool {
+		return pendingCount(t, bus.redis, "test.redis", "test-consumer") == 0
+	})
+}
+
+// TestRedisTransport_Broadcast verifies every broadcast subscription receives
+// messages published after it subscribed, and its group is removed on exit.
+func TestRedisTransport_Broadcast(t *testing.T) {
+	bus, _ := newTestRedisBus(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := bus.Publish(ctx, "test.redis", message.NewMessage("old", []byte("{}"))); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	var chans []chan string
+	for range 2 {
+		got := make(chan string, 2)
+		errCh, err := bus.Subscribe(ctx, "test.redis", func(_ context.Context, msg *message.Message) error {
+			got <- msg.UUID
+			return nil
+		}, WithBroadcast())
+		if err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+		drain(errCh)
+		chans = append(chans, got)
+	}
+
+	if err := bus.Publish(ctx, "test.redis", message.NewMessage("new", []byte("{}"))); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	for i, got := range chans {
+		select {
+		case id := <-got:
+			if id != "new" {
+				t.Errorf("subscription %d: got %s, want new", i, id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("subscription %d: timed out waiting for delivery", i)
+		}
+	}
+
+	cancel()
+	bus.wg.Wait()
+	groups, err := bus.redis.client.XInfoGroups(context.Background(), streamKey("test.redis")).Result()
+	if err != nil {
+		t.Fatalf("xinfo groups: %v", err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("broadcast groups left after unsubscribe: %v", groups)
+	}
+}
+
+// TestRedisStreams_Nack verifies a Nacked message is delivered again.
+func TestRedisStreams_Nack(t *testing.T) {
+	r := newTestRedisStreams(t)
+	defer r.Close() //nolint:errcheck
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	msgs, err := r.subscribe(ctx, &wg, "test.redis", "group", "0", false)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := r.Publish("test.redis", message.NewMessage("uuid-1", []byte("{}"))); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	receive(t, msgs).Nack()
+	again := receive(t, msgs)
+	if again.UUID != "uuid-1" {
+		t.Fatalf("redelivered %s, want uuid-1", again.UUID)
+	}
+	again.Ack()
+	waitFor(t, "acknowledgement", func() bool { return pendingCount(t, r, "test.redis", "group") == 0 })
+}
+
+// TestRedisStreams_ClaimStale verifies a message left pending by a crashed
+// consumer is claimed and delivered once it has been idle for claimIdle.
+func TestRedisStreams_ClaimStale(t *testing.T) {
+	r := newTestRedisStreams(t)
+	defer r.Close() //nolint:errcheck
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := r.createGroup(ctx, streamKey("test.redis"), "group", "0"); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := r.Publish("test.redis", message.NewMessage("uuid-1", []byte("{}"))); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	// A consumer reads the entry and dies before acknowledging it.
+	err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: "group", Consumer: "crashed", Streams: []string{streamKey("test.redis"), ">"}, Count: 1,
+	}).Err()
+	if err != nil {
+		t.Fatalf("xreadgroup: %v", err)
+	}
+
+	msgs, err := r.subscribe(ctx, &wg, "test.redis", "group", "0", false)
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	msg := receive(t, msgs)
+	if msg.UUID != "uuid-1" {
+		t.Fatalf("claimed %s, want uuid-1", msg.UUID)
+	}
+	msg.Ack()
+	waitFor(t, "acknowledgement", func() bool { return pendingCount(t, r, "test.redis", "group") == 0 })
+}
+
+// TestRedisStreams_GroupStart verifies a new partition group starts before
+// the base group's oldest pending entry.
+func TestRedisStreams_GroupStart(t *testing.T) {
+	r := newTestRedisStreams(t)
+	defer r.Close() //nolint:errcheck
+	ctx := context.Background()
+	stream := streamKey("test.redis")
+
+	if start, err := r.groupStart(ctx, "test.redis", "group"); err != nil || start != "0" {
+		t.Fatalf("missing stream: got %q, %v; want 0", start, err)
+	}
+
+	for _, id := range []string{"1-1", "1-2", "2-0"} {
+		if err := r.client.XAdd(ctx, &redis.XAddArgs{Stream: stream, ID: id, Values: []any{"uuid", id}}).Err(); err != nil {
+			t.Fatalf("xadd: %v", err)
+		}
+	}
+	if err := r.createGroup(ctx, stream, "group", "0"); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: "group", Consumer: "c", Streams: []string{stream, ">"}, Count: 3,
+	}).Err(); err != nil {
+		t.Fatalf("xreadgroup: %v", err)
+	}
+	if err := r.client.XAck(ctx, stream, "group", "1-1").Err(); err != nil {
+		t.Fatalf("xack: %v", err)
+	}
+
+	if start, err := r.groupStart(ctx, "test.redis", "group"); err != nil || start != "1-1" {
+		t.Errorf("pending entries: got %q, %v; want 1-1", start, err)
+	}
+	if err := r.client.XAck(ctx, stream, "group", "1-2", "2-0").Err(); err != nil {
+		t.Fatalf("xack: %v", err)
+	}
+	if start, err := r.groupStart(ctx, "test.redis", "group"); err != nil || start != "2-0" {
+		t.Errorf("all acknowledged: got %q, %v; want 2-0", start, err)
+	}
+}
+
+func TestPreviousStreamID(t *testing.T) {
+	tests := []struct {
+		id, want string
+	}{
+		{"5-3", "5-2"},
+		{"5-0", "4-18446744073709551615"},
+		{"0-0", "0"},
+	}
+	for _, tt := range tests {
+		if got, err := previousStreamID(tt.id); err != nil || got != tt.want {
+			t.Errorf("previousStreamID(%q) = %q, %v; want %q", tt.id, got, err, tt.want)
+		}
+	}
+	if _, err := previousStreamID("bogus"); err == nil {
+		t.Error("previousStreamID(bogus): expected error")
+	}
+}
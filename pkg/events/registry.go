@@ -0,0 +1,224 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Metadata keys set on every typed event.
+const (
+	// MetaEventID is a stable, publish-time event identifier. The inbox
+	// deduplicates on it; the Watermill message UUID is used as a fallback
+	// for messages without one.
+	MetaEventID = "event_id"
+	// MetaEventVersion is the payload schema version.
+	MetaEventVersion = "event_version"
+)
+
+var (
+	// ErrUnregisteredEvent is returned when a typed helper is used with a type
+	// that was never passed to Register.
+	ErrUnregisteredEvent = errors.New("events: event type not registered")
+
+	// ErrUnknownEventVersion is returned by typed subscribers when a message's
+	// event_version is missing, newer than the registered version, or cannot
+	// be upcast to it.
+	ErrUnknownEventVersion = errors.New("events: unknown event version")
+)
+
+// Identifiable is implemented by events that carry their own publish-time ID.
+// PublishTyped uses it as the event_id metadata so the payload and metadata
+// agree; events without it get a random event_id per publish.
+type Identifiable interface {
+	GetEventID() uuid.UUID
+}
+
+// eventType describes a registered event payload type.
+type eventType struct {
+	topic   string
+	version int
+	goType  reflect.Type
+}
+
+var registry = struct {
+	sync.RWMutex
+	byType  map[reflect.Type]eventType
+	byTopic map[string]eventType
+}{
+	byType:  map[reflect.Type]eventType{},
+	byTopic: map[string]eventType{},
+}
+
+// Register associates the payload type T with topic and its current schema version.
+// Each type and each topic may be registered once; call it from an init function
+// of the package that owns the event:
+//
+//	func init() {
+//	    events.Register[domainevents.ItemCreatedEvent](domainevents.TopicItemCreated, 1)
+//	}
+//
+// Register panics on duplicate or invalid registrations, which are programming errors.
+func Register[T any](topic string, version int) {
+	t := reflect.TypeFor[T]()
+	if topic == "" || version < 1 {
+		panic(fmt.Sprintf("events: invalid registration of %s: topic=%q version=%d", t, topic, version))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if existing, ok := registry.byType[t]; ok {
+		panic(fmt.Sprintf("events: %s already registered for topic %q", t, existing.topic))
+	}
+	if existing, ok := registry.byTopic[topic]; ok {
+		panic(fmt.Sprintf("events: topic %q already registered for %s", topic, existing.goType))
+	}
+	et := eventType{topic: topic, version: version, goType: t}
+	registry.byType[t] = et
+	registry.byTopic[topic] = et
+}
+
+// lookup returns the registration for T.
+func lookup[T any]() (eventType, error) {
+	t := reflect.TypeFor[T]()
+	registry.RLock()
+	defer registry.RUnlock()
+	et, ok := registry.byType[t]
+	if !ok {
+		return eventType{}, fmt.Errorf("%w: %s", ErrUnregisteredEvent, t)
+	}
+	return et, nil
+}
+
+// TopicOf returns the topic T was registered with.
+func TopicOf[T any]() (string, error) {
+	et, err := lookup[T]()
+	return et.topic, err
+}
+
+// NewTypedMessage encodes event as JSON and returns it with its registered topic.
+// The message carries event_id, event_version, event_time, the OTel trace context from ctx
+// and, for an AggregateEvent or with WithPartitionKey, a partition_key.
+func NewTypedMessage[T any](ctx context.Context, event T, opts ...PublishOption) (string, *message.Message, error) {
+	et, err := lookup[T]()
+	if err != nil {
+		return "", nil, err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", nil, fmt.Errorf("events: marshal %s: %w", et.goType, err)
+	}
+
+	eventID := watermill.NewUUID()
+	if ider, ok := any(event).(Identifiable); ok {
+		eventID = ider.GetEventID().String()
+	}
+
+	msg := message.NewMessage(watermill.NewUUID(), payload)
+	msg.Metadata.Set(MetaEventID, eventID)
+	msg.Metadata.Set(MetaEventVersion, strconv.Itoa(et.version))
+	msg.Metadata.Set(MetaEventTime, time.Now().UTC().Format(time.RFC3339Nano))
+
+	var cfg publishConfig
+	if agg, ok := any(event).(AggregateEvent); ok {
+		cfg.partitionKey = agg.GetAggregateID().String()
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.partitionKey != "" {
+		SetPartitionKey(msg, cfg.partitionKey)
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	for k, v := range carrier {
+		msg.Metadata.Set(k, v)
+	}
+	return et.topic, msg, nil
+}
+
+// PublishTyped encodes event and publishes it to its registered topic through pub.
+// Pass a publisher from EventBus.NewTxPublisher to publish atomically with a
+// database transaction.
+func PublishTyped[T any](ctx context.Context, pub message.Publisher, event T, opts ...PublishOption) error {
+	topic, msg, err := NewTypedMessage(ctx, event, opts...)
+	if err != nil {
+		return err
+	}
+	if err := pub.Publish(topic, msg); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// DecodeTyped validates msg's event_version against T's registration, upcasts
+// older payloads to the current version and unmarshals the result. Returns
+// ErrUnknownEventVersion for missing, newer or non-upcastable versions.
+func DecodeTyped[T any](msg *message.Message) (T, error) {
+	var event T
+	et, err := lookup[T]()
+	if err != nil {
+		return event, err
+	}
+
+	raw := msg.Metadata.Get(MetaEventVersion)
+	version, err := strconv.Atoi(raw)
+	if err != nil {
+		return event, fmt.Errorf("%w: %s version %q (current: %d)", ErrUnknownEventVersion, et.topic, raw, et.version)
+	}
+	payload, err := Upcast(et.topic, version, msg.Payload)
+	if err != nil {
+		return event, err
+	}
+
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return event, fmt.Errorf("events: unmarshal %s: %w", et.goType, err)
+	}
+	return event, nil
+}
+
+// SubscribeTyped subscribes handler to T's registered topic, decoding each
+// message with DecodeTyped before invoking it. Retry, dead-letter and inbox
+// behavior are the same as Bus.Subscribe, except that a message that fails to
+// decode is dead-lettered without retrying.
+func SubscribeTyped[T any](
+	ctx context.Context,
+	bus Bus,
+	handler func(context.Context, T) error,
+	opts ...SubscribeOption,
+) (<-chan error, error) {
+	et, err := lookup[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	opts = append([]SubscribeOption{WithHandlerName(handlerName(handler))}, opts...)
+	return bus.Subscribe(ctx, et.topic, TypedHandler(handler), opts...)
+}
+
+// TypedHandler adapts handler to a Handler that decodes each message with
+// DecodeTyped. Decode failures are marked Permanent: a payload that cannot be
+// decoded never will be. Use it to pass typed handlers to Bus.Subscribe or
+// EventBus.Replay.
+func TypedHandler[T any](handler func(context.Context, T) error) Handler {
+	return func(ctx context.Context, msg *message.Message) error {
+		event, err := DecodeTyped[T](msg)
+		if err != nil {
+			return Permanent(err)
+		}
+		return handler(ctx, event)
+	}
+}
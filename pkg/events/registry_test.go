@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/google/uuid"
+)
+
+// The registry is process-global, so these tests use their own types and topics.
+
+type testRegisteredEvent struct {
+	EventID uuid.UUID `json:"event_id"`
+	Name    string    `json:"name"`
+}
+
+func (e testRegisteredEvent) GetEventID() uuid.UUID { return e.EventID }
+
+type testUnregisteredEvent struct{}
+
+func init() {
+	Register[testRegisteredEvent]("test.registered", 2)
+}
+
+// TestRegister_PanicsOnDuplicate verifies duplicate types and topics are rejected.
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	type otherEvent struct{}
+
+	for name, register := range map[string]func(){
+		"duplicate type":  func() { Register[testRegisteredEvent]("test.other", 1) },
+		"duplicate topic": func() { Register[otherEvent]("test.registered", 1) },
+		"invalid version": func() { Register[otherEvent]("test.invalid", 0) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			register()
+		})
+	}
+}
+
+// TestTypedMessage_RoundTrip verifies topic, metadata and payload survive encode/decode.
+func TestTypedMessage_RoundTrip(t *testing.T) {
+	evt := testRegisteredEvent{EventID: uuid.New(), Name: "widget"}
+
+	topic, msg, err := NewTypedMessage(context.Background(), evt)
+	if err != nil {
+		t.Fatalf("NewTypedMessage: %v", err)
+	}
+	if topic != "test.registered" {
+		t.Errorf("topic: got %q, want %q", topic, "test.registered")
+	}
+	if got := msg.Metadata.Get(MetaEventID); got != evt.EventID.String() {
+		t.Errorf("event_id: got %q, want %q", got, evt.EventID)
+	}
+	if got := msg.Metadata.Get(MetaEventVersion); got != "2" {
+		t.Errorf("event_version: got %q, want %q", got, "2")
+	}
+
+	got, err := DecodeTyped[testRegisteredEvent](msg)
+	if err != nil {
+		t.Fatalf("DecodeTyped: %v", err)
+	}
+	if got != evt {
+		t.Errorf("decoded: got %+v, want %+v", got, evt)
+	}
+}
+
+// TestDecodeTyped_RejectsUnknownVersion verifies missing and mismatched versions fail.
+func TestDecodeTyped_RejectsUnknownVersion(t *testing.T) {
+	for _, version := range []string{"", "1", "3", "v2"} {
+		msg := message.NewMessage("id", []byte(`{}`))
+		if version != "" {
+			msg.Metadata.Set(MetaEventVersion, version)
+		}
+		if _, err := DecodeTyped[testRegisteredEvent](msg); !errors.Is(err, ErrUnknownEventVersion) {
+			t.Errorf("version %q: expected ErrUnknownEventVersion, got %v", version, err)
+		}
+	}
+}
+
+// TestTypedHelpers_Unregistered verifies unregistered types are reported, not guessed.
+func TestTypedHelpers_Unregistered(t *testing.T) {
+	if _, err := TopicOf[testUnregisteredEvent](); !errors.Is(err, ErrUnregisteredEvent) {
+		t.Errorf("TopicOf: expected ErrUnregisteredEvent, got %v", err)
+	}
+	if _, _, err := NewTypedMessage(context.Background(), testUnregisteredEvent{}); !errors.Is(err, ErrUnregisteredEvent) {
+		t.Errorf("NewTypedMessage: expected ErrUnregisteredEvent, got %v", err)
+	}
+	if _, err := DecodeTyped[testUnregisteredEvent](message.NewMessage("id", nil)); !errors.Is(err, ErrUnregisteredEvent) {
+		t.Errorf("DecodeTyped: expected ErrUnregisteredEvent, got %v", err)
+	}
+}
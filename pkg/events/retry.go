@@ -0,0 +1,140 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// Retry defaults, applied to zero RetryPolicy fields.
+const (
+	DefaultMaxAttempts     = 3
+	DefaultRetryBaseDelay  = time.Second
+	DefaultRetryMultiplier = 2.0
+)
+
+// RetryPolicy controls how a failing handler is retried before its message is
+// dead-lettered. Zero values fall back to the defaults above, so the zero
+// RetryPolicy retries 3 times in total, waiting 1s and then 2s.
+type RetryPolicy struct {
+	MaxAttempts int           // handler calls per delivery, including the first
+	BaseDelay   time.Duration // wait before the second attempt
+	Multiplier  float64       // growth of the wait per attempt; values below 1 use the default
+	MaxDelay    time.Duration // upper bound of a single wait; 0 means unbounded
+	Jitter      float64       // randomizes each wait by up to ±Jitter of its length (0–1)
+
+	// NonRetryable reports errors that no retry can fix. Such errors, like
+	// those marked with Permanent, dead-letter the message immediately.
+	NonRetryable func(error) bool
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultMaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = DefaultRetryBaseDelay
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryMultiplier
+	}
+	p.Jitter = min(max(p.Jitter, 0), 1)
+	return p
+}
+
+// backoff returns the wait after the given failed attempt (1-based).
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.BaseDelay) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d += d * p.Jitter * (2*rand.Float64() - 1) //nolint:gosec // jitter needs no crypto randomness
+	}
+	return time.Duration(d)
+}
+
+// retryable reports whether err is worth another attempt.
+func (p RetryPolicy) retryable(err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+	return p.NonRetryable == nil || !p.NonRetryable(err)
+}
+
+// permanentError marks an error as non-retryable.
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as non-retryable: the bus stops retrying and dead-letters
+// the message right away. Use it for failures a retry cannot fix, such as a
+// malformed payload. Returns nil if err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err, or any error it wraps, was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// retryWithBackoff calls handler up to policy.MaxAttempts times, waiting
+// policy.backoff between attempts. Returns nil on first success; returns the
+// last error once attempts are exhausted or the error is not retryable.
+func retryWithBackoff(
+	ctx context.Context,
+	msg *message.Message,
+	handler Handler,
+	policy RetryPolicy,
+	log logger.Logger,
+) error {
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		if err = handler(contextWithAttempt(ctx, attempt), msg); err == nil {
+			return nil
+		}
+		if !policy.retryable(err) {
+			return fmt.Errorf("events: handler failed permanently on attempt %d: %w", attempt, err)
+		}
+		if attempt < policy.MaxAttempts {
+			delay := policy.backoff(attempt)
+			log.WarnContext(ctx, "events: handler failed, retrying",
+				"attempt", attempt,
+				"max_attempts", policy.MaxAttempts,
+				"next_delay", delay,
+				"error", err,
+			)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+	}
+	return fmt.Errorf("events: handler failed after %d attempts: %w", policy.MaxAttempts, err)
+}
+
+// withAttemptTimeout bounds each handler call to timeout; 0 leaves it unbounded.
+func withAttemptTimeout(handler Handler, timeout time.Duration) Handler {
+	if timeout <= 0 {
+		return handler
+	}
+	return func(ctx context.Context, msg *message.Message) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return handler(ctx, msg)
+	}
+}
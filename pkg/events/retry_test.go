@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// TestRetryPolicy_Defaults verifies zero fields fall back to the defaults.
+func TestRetryPolicy_Defaults(t *testing.T) {
+	p := RetryPolicy{Jitter: 5}.withDefaults()
+	if p.MaxAttempts != DefaultMaxAttempts {
+		t.Errorf("MaxAttempts: got %d, want %d", p.MaxAttempts, DefaultMaxAttempts)
+	}
+	if p.BaseDelay != DefaultRetryBaseDelay {
+		t.Errorf("BaseDelay: got %s, want %s", p.BaseDelay, DefaultRetryBaseDelay)
+	}
+	if p.Multiplier != DefaultRetryMultiplier {
+		t.Errorf("Multiplier: got %v, want %v", p.Multiplier, DefaultRetryMultiplier)
+	}
+	if p.Jitter != 1 {
+		t.Errorf("Jitter: got %v, want clamped to 1", p.Jitter)
+	}
+}
+
+// TestRetryPolicy_Backoff verifies the exponential curve, its cap and jitter bounds.
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, Multiplier: 3, MaxDelay: time.Second}.withDefaults()
+	for attempt, want := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 300 * time.Millisecond,
+		3: 900 * time.Millisecond,
+		4: time.Second,
+	} {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("attempt %d: got %s, want %s", attempt, got, want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for range 100 {
+		if got := p.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("jittered delay %s outside [50ms, 150ms]", got)
+		}
+	}
+}
+
+// TestPermanent verifies Permanent marks errors and keeps them unwrappable.
+func TestPermanent(t *testing.T) {
+	if Permanent(nil) != nil {
+		t.Error("expected Permanent(nil) to be nil")
+	}
+
+	base := errors.New("bad payload")
+	err := Permanent(base)
+	if !IsPermanent(err) || !errors.Is(err, base) {
+		t.Errorf("expected permanent error wrapping %v, got %v", base, err)
+	}
+	if IsPermanent(base) {
+		t.Error("expected plain error not to be permanent")
+	}
+}
+
+// TestRetryWithBackoff_NonRetryable verifies permanent and classified errors stop retries.
+func TestRetryWithBackoff_NonRetryable(t *testing.T) {
+	errSkip := errors.New("skip")
+	policy := testRetryPolicy(time.Millisecond)
+	policy.NonRetryable = func(err error) bool { return errors.Is(err, errSkip) }
+
+	for name, handlerErr := range map[string]error{
+		"permanent":  Permanent(errors.New("bad payload")),
+		"classified": errSkip,
+	} {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			handler := func(_ context.Context, _ *message.Message) error {
+				calls++
+				return handlerErr
+			}
+			err := retryWithBackoff(context.Background(), message.NewMessage("id", nil), handler, policy, nopLogger())
+			if !errors.Is(err, handlerErr) {
+				t.Errorf("expected %v, got %v", handlerErr, err)
+			}
+			if calls != 1 {
+				t.Errorf("expected 1 call, got %d", calls)
+			}
+		})
+	}
+}
+
+// TestWithAttemptTimeout verifies each attempt gets its own deadline.
+func TestWithAttemptTimeout(t *testing.T) {
+	handler := withAttemptTimeout(func(ctx context.Context, _ *message.Message) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Millisecond)
+
+	if err := handler(context.Background(), message.NewMessage("id", nil)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
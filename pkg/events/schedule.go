@@ -0,0 +1,358 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// scheduledTable holds messages waiting for their delivery time.
+// Created on first use, like the inbox table.
+const scheduledTable = "events_scheduled"
+
+// scheduleBatchSize is how many due messages DeliverDue publishes per transaction.
+const scheduleBatchSize = 100
+
+// scheduleMaxAttempts is how many failed deliveries of a scheduled message
+// DeliverDue tolerates before it marks the message failed and skips it.
+const scheduleMaxAttempts = 10
+
+// ErrScheduledNotFound is returned when a scheduled message does not exist,
+// either because it was never scheduled or because it was already delivered
+// or cancelled.
+var ErrScheduledNotFound = errors.New("events: scheduled message not found")
+
+// ScheduledMessage is a message waiting to be published to Topic at DeliverAt.
+// Attempts and LastError record failed deliveries; a message with FailedAt set
+// failed scheduleMaxAttempts times and is no longer delivered.
+type ScheduledMessage struct {
+	UUID      string            `json:"uuid"`
+	Topic     string            `json:"topic"`
+	Payload   json.RawMessage   `json:"payload"`
+	Metadata  map[string]string `json:"metadata"`
+	DeliverAt time.Time         `json:"deliver_at"`
+	CreatedAt time.Time         `json:"created_at"`
+	Attempts  int               `json:"attempts"`
+	LastError string            `json:"last_error,omitempty"`
+	FailedAt  *time.Time        `json:"failed_at,omitempty"`
+}
+
+// scheduledColumns are the columns scanScheduled reads, in order.
+const scheduledColumns = `uuid, topic, payload, metadata, deliver_at, created_at, attempts, last_error, failed_at`
+
+// initSchedule creates the scheduled messages table if it does not exist.
+func (q *EventBus) initSchedule(ctx context.Context) error {
+	if q.scheduleReady.Load() {
+		return nil
+	}
+	_, err := q.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+scheduledTable+` (
+			uuid       TEXT        PRIMARY KEY,
+			topic      TEXT        NOT NULL,
+			payload    BYTEA       NOT NULL,
+			metadata   JSONB       NOT NULL DEFAULT '{}',
+			deliver_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		ALTER TABLE `+scheduledTable+`
+			ADD COLUMN IF NOT EXISTS attempts   INT         NOT NULL DEFAULT 0,
+			ADD COLUMN IF NOT EXISTS last_error TEXT,
+			ADD COLUMN IF NOT EXISTS failed_at  TIMESTAMPTZ;
+		CREATE INDEX IF NOT EXISTS `+scheduledTable+`_deliver_at_idx ON `+scheduledTable+` (deliver_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("events: init schedule: %w", err)
+	}
+	q.scheduleReady.Store(true)
+	return nil
+}
+
+// PublishAt stores msgs for delivery to topic at deliverAt. The worker's
+// RunScheduler publishes them once they are due; a time in the past delivers
+// on its next poll. The message UUID identifies the scheduled message for
+// CancelScheduled. OTel trace context from ctx is injected as in Publish.
+// Returns ErrInvalidTopic for a topic name Watermill cannot store; the topic's
+// tables are created here, since the scheduler publishes within a transaction
+// that cannot create them.
+func (q *EventBus) PublishAt(ctx context.Context, topic string, deliverAt time.Time, msgs ...*message.Message) error {
+	if !topicNamePattern.MatchString(topic) {
+		return fmt.Errorf("%w %q", ErrInvalidTopic, topic)
+	}
+	if err := q.initSchedule(ctx); err != nil {
+		return err
+	}
+	if err := q.initTopic(topic); err != nil {
+		return err
+	}
+	injectTrace(ctx, msgs)
+
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("events: begin schedule tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, msg := range msgs {
+		metadata, err := json.Marshal(msg.Metadata)
+		if err != nil {
+			return fmt.Errorf("events: marshal metadata of %s: %w", msg.UUID, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+scheduledTable+` (uuid, topic, payload, metadata, deliver_at) VALUES ($1, $2, $3, $4, $5)`,
+			msg.UUID, topic, []byte(msg.Payload), metadata, deliverAt,
+		); err != nil {
+			return fmt.Errorf("events: schedule %s to %s: %w", msg.UUID, topic, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("events: commit schedule tx: %w", err)
+	}
+	return nil
+}
+
+// PublishAfter stores msgs for delivery to topic once delay has elapsed.
+// See PublishAt.
+func (q *EventBus) PublishAfter(ctx context.Context, topic string, delay time.Duration, msgs ...*message.Message) error {
+	return q.PublishAt(ctx, topic, time.Now().Add(delay), msgs...)
+}
+
+// CancelScheduled removes a scheduled message before it is delivered.
+// Returns ErrScheduledNotFound if it does not exist or was already delivered.
+func (q *EventBus) CancelScheduled(ctx context.Context, uuid string) error {
+	if err := q.initSchedule(ctx); err != nil {
+		return err
+	}
+	res, err := q.db.ExecContext(ctx, `DELETE FROM `+scheduledTable+` WHERE uuid = $1`, uuid)
+	if err != nil {
+		return fmt.Errorf("events: cancel scheduled %s: %w", uuid, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrScheduledNotFound
+	}
+	q.log.InfoContext(ctx, "events: scheduled message cancelled", "uuid", uuid)
+	return nil
+}
+
+// ListScheduled returns up to limit pending scheduled messages, earliest
+// delivery first, including those marked failed. An empty topic lists all
+// topics.
+func (q *EventBus) ListScheduled(ctx context.Context, topic string, limit int) ([]ScheduledMessage, error) {
+	if err := q.initSchedule(ctx); err != nil {
+		return nil, err
+	}
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM `+scheduledTable+`
+		WHERE $1 = '' OR topic = $1
+		ORDER BY deliver_at, uuid
+		LIMIT $2`, topic, limit)
+	if err != nil {
+		return nil, fmt.Errorf("events: list scheduled: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []ScheduledMessage
+	for rows.Next() {
+		sm, err := scanScheduled(rows)
+		if err != nil {
+			return nil, fmt.Errorf("events: list scheduled: %w", err)
+		}
+		out = append(out, sm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("events: list scheduled: %w", err)
+	}
+	return out, nil
+}
+
+// DeliverDue publishes up to one batch of due scheduled messages and returns
+// how many were delivered. Rows are claimed with FOR UPDATE SKIP LOCKED and
+// published through a transactional publisher, so several workers can run the
+// scheduler and each message is handed to its topic exactly once.
+//
+// Each message is published under a savepoint, so one that fails does not
+// roll back the rest of its batch: its attempts are counted instead, and
+// after scheduleMaxAttempts it is marked failed (failed_at) and skipped until
+// an operator cancels it. The failures are returned after the batch commits.
+func (q *EventBus) DeliverDue(ctx context.Context) (int, error) {
+	if err := q.initSchedule(ctx); err != nil {
+		return 0, err
+	}
+	tx, err := q.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("events: begin scheduler tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	due, err := claimDue(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	pub, err := q.NewTxPublisher(tx)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		delivered []string
+		failures  []error
+	)
+	for _, sm := range due {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT scheduled_delivery`); err != nil {
+			return 0, fmt.Errorf("events: savepoint before %s: %w", sm.UUID, err)
+		}
+		msg := message.NewMessage(sm.UUID, []byte(sm.Payload))
+		for k, v := range sm.Metadata {
+			msg.Metadata.Set(k, v)
+		}
+		if err := pub.Publish(sm.Topic, msg); err != nil { //nolint:contextcheck
+			err = fmt.Errorf("events: deliver scheduled %s to %s: %w", sm.UUID, sm.Topic, err)
+			if recErr := q.recordScheduledFailure(ctx, tx, sm, err); recErr != nil {
+				return 0, recErr
+			}
+			failures = append(failures, err)
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT scheduled_delivery`); err != nil {
+			return 0, fmt.Errorf("events: release savepoint of %s: %w", sm.UUID, err)
+		}
+		delivered = append(delivered, sm.UUID)
+	}
+	if len(delivered) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`DELETE FROM `+scheduledTable+` WHERE uuid = ANY($1)`, delivered,
+		); err != nil {
+			return 0, fmt.Errorf("events: remove delivered messages: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("events: commit scheduler tx: %w", err)
+	}
+	return len(delivered), errors.Join(failures...)
+}
+
+// claimDue locks up to scheduleBatchSize due messages, earliest first,
+// skipping messages locked by other schedulers and messages marked failed.
+func claimDue(ctx context.Context, tx *sql.Tx) ([]ScheduledMessage, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+scheduledColumns+`
+		FROM `+scheduledTable+`
+		WHERE deliver_at <= now() AND failed_at IS NULL
+		ORDER BY deliver_at, uuid
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, scheduleBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("events: claim due messages: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var due []ScheduledMessage
+	for rows.Next() {
+		sm, err := scanScheduled(rows)
+		if err != nil {
+			return nil, fmt.Errorf("events: claim due messages: %w", err)
+		}
+		due = append(due, sm)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("events: claim due messages: %w", err)
+	}
+	return due, nil
+}
+
+// recordScheduledFailure rolls back the failed publish of sm to the savepoint
+// and counts the attempt, marking sm failed once it reaches
+// scheduleMaxAttempts.
+func (q *EventBus) recordScheduledFailure(ctx context.Context, tx *sql.Tx, sm ScheduledMessage, pubErr error) error {
+	if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT scheduled_delivery`); err != nil {
+		return fmt.Errorf("events: roll back delivery of %s: %w", sm.UUID, err)
+	}
+	var (
+		attempts int
+		gaveUp   bool
+	)
+	if err := tx.QueryRowContext(ctx, `
+		UPDATE `+scheduledTable+`
+		SET attempts = attempts + 1, last_error = $2,
+			failed_at = CASE WHEN attempts + 1 >= $3 THEN now() END
+		WHERE uuid = $1
+		RETURNING attempts, failed_at IS NOT NULL`,
+		sm.UUID, pubErr.Error(), scheduleMaxAttempts,
+	).Scan(&attempts, &gaveUp); err != nil {
+		return fmt.Errorf("events: record failure of %s: %w", sm.UUID, err)
+	}
+	if gaveUp {
+		q.metrics.scheduledFailed.Add(ctx, 1, metric.WithAttributes(attribute.String("topic", sm.Topic)))
+		q.log.ErrorContext(ctx, "events: giving up on scheduled message, marked failed",
+			"uuid", sm.UUID, "topic", sm.Topic, "attempts", attempts, "error", pubErr)
+	}
+	return nil
+}
+
+// RunScheduler calls DeliverDue every interval until ctx is cancelled. A full
+// batch is followed immediately by the next one. Failures are logged and
+// retried on the next tick, up to scheduleMaxAttempts per message.
+func (q *EventBus) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			n, err := q.DeliverDue(ctx)
+			if n > 0 {
+				q.log.InfoContext(ctx, "events: scheduled messages delivered", "count", n)
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					q.log.ErrorContext(ctx, "events: scheduler delivery failed", "error", err)
+				}
+				break
+			}
+			if n < scheduleBatchSize {
+				break
+			}
+		}
+	}
+}
+
+func scanScheduled(row rowScanner) (ScheduledMessage, error) {
+	var (
+		sm        ScheduledMessage
+		payload   []byte
+		rawMeta   []byte
+		lastError sql.NullString
+		failedAt  sql.NullTime
+	)
+	if err := row.Scan(&sm.UUID, &sm.Topic, &payload, &rawMeta, &sm.DeliverAt, &sm.CreatedAt,
+		&sm.Attempts, &lastError, &failedAt); err != nil {
+		return ScheduledMessage{}, err
+	}
+	sm.Payload = payload
+	sm.LastError = lastError.String
+	if failedAt.Valid {
+		sm.FailedAt = &failedAt.Time
+	}
+	sm.Metadata = map[string]string{}
+	if len(rawMeta) > 0 {
+		if err := json.Unmarshal(rawMeta, &sm.Metadata); err != nil {
+			return ScheduledMessage{}, fmt.Errorf("unmarshal metadata: %w", err)
+		}
+	}
+	return sm, nil
+}
@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// scheduledRow is a rowScanner returning a fixed events_scheduled row.
+type scheduledRow struct {
+	metadata []byte
+}
+
+func (r scheduledRow) Scan(dest ...any) error {
+	*dest[0].(*string) = "msg-1"
+	*dest[1].(*string) = "item.expire"
+	*dest[2].(*[]byte) = []byte(`{"item_id":"1"}`)
+	*dest[3].(*[]byte) = r.metadata
+	*dest[4].(*time.Time) = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	*dest[5].(*time.Time) = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	*dest[6].(*int) = 2
+	*dest[7].(*sql.NullString) = sql.NullString{String: "boom", Valid: true}
+	*dest[8].(*sql.NullTime) = sql.NullTime{}
+	return nil
+}
+
+// TestScanScheduled verifies a stored row maps back to a ScheduledMessage.
+func TestScanScheduled(t *testing.T) {
+	sm, err := scanScheduled(scheduledRow{metadata: []byte(`{"event_id":"evt-1"}`)})
+	if err != nil {
+		t.Fatalf("scanScheduled: %v", err)
+	}
+	if sm.UUID != "msg-1" || sm.Topic != "item.expire" || string(sm.Payload) != `{"item_id":"1"}` {
+		t.Errorf("unexpected message: %+v", sm)
+	}
+	if sm.Metadata[MetaEventID] != "evt-1" {
+		t.Errorf("metadata: got %v", sm.Metadata)
+	}
+	if sm.Attempts != 2 || sm.LastError != "boom" || sm.FailedAt != nil {
+		t.Errorf("failures: attempts=%d last_error=%q failed_at=%v", sm.Attempts, sm.LastError, sm.FailedAt)
+	}
+	if !sm.DeliverAt.After(sm.CreatedAt) {
+		t.Errorf("times: deliver_at=%v created_at=%v", sm.DeliverAt, sm.CreatedAt)
+	}
+
+	if _, err := scanScheduled(scheduledRow{metadata: []byte(`not json`)}); err == nil {
+		t.Error("expected error for invalid metadata")
+	}
+}
+
+// TestPublishAt_InvalidTopic verifies topics Watermill cannot store are
+// rejected before anything is scheduled.
+func TestPublishAt_InvalidTopic(t *testing.T) {
+	q, _ := newFakeBus(t)
+	err := q.PublishAt(context.Background(), "item created", time.Now())
+	if !errors.Is(err, ErrInvalidTopic) {
+		t.Errorf("got %v, want ErrInvalidTopic", err)
+	}
+}
+
+// TestDeliverDue_FailingMessage verifies a message that fails to publish does
+// not roll back the rest of its batch, and is marked failed and skipped after
+// scheduleMaxAttempts.
+func TestDeliverDue_FailingMessage(t *testing.T) {
+	q, f := newFakeBus(t)
+	due := time.Now().Add(-time.Minute)
+	f.tables.scheduled = []fakeScheduled{
+		{uuid: "a", topic: "missing.topic", deliverAt: due},
+		{uuid: "b", topic: "item.expire", deliverAt: due},
+		{uuid: "c", topic: "item.expire", deliverAt: due.Add(time.Hour)},
+	}
+	f.failTopics["missing.topic"] = true
+	ctx := context.Background()
+
+	n, err := q.DeliverDue(ctx)
+	if n != 1 || err == nil {
+		t.Fatalf("first batch: got %d, %v; want 1 delivered and the failure", n, err)
+	}
+	tables := f.snapshot()
+	if !slices.Equal(tables.published, []string{"b"}) {
+		t.Errorf("published: got %v, want [b]", tables.published)
+	}
+	if len(tables.scheduled) != 2 || tables.scheduled[0].uuid != "a" || tables.scheduled[0].attempts != 1 {
+		t.Fatalf("scheduled after first batch: %+v", tables.scheduled)
+	}
+	if tables.scheduled[0].lastError == "" {
+		t.Error("failure not recorded")
+	}
+
+	for i := 2; i <= scheduleMaxAttempts; i++ {
+		if _, err := q.DeliverDue(ctx); err == nil {
+			t.Fatalf("attempt %d: expected the failure", i)
+		}
+	}
+	tables = f.snapshot()
+	if a := tables.scheduled[0]; !a.failed || a.attempts != scheduleMaxAttempts {
+		t.Errorf("after %d attempts: %+v, want marked failed", scheduleMaxAttempts, a)
+	}
+	if n, err := q.DeliverDue(ctx); n != 0 || err != nil {
+		t.Errorf("after marking failed: got %d, %v; want the message skipped", n, err)
+	}
+	if len(f.snapshot().published) != 1 {
+		t.Error("failed message published")
+	}
+}
@@ -0,0 +1,95 @@
+package events
+
+import (
+	"fmt"
+)
+
+// Upcaster rewrites a JSON payload from one schema version to the next.
+// It receives the payload as published at version N and returns it in the
+// shape of version N+1. Upcasters should also update any version field the
+// payload itself carries.
+type Upcaster func(payload []byte) ([]byte, error)
+
+// upcasters holds the registered upcasters per topic, keyed by the version
+// they upgrade from. Guarded by registry's mutex.
+var upcasters = map[string]map[int]Upcaster{}
+
+// RegisterUpcaster registers fn to upgrade topic's payloads from fromVersion to
+// fromVersion+1. Typed subscribers chain upcasters so a message published at
+// any older version reaches the handler in the current shape:
+//
+//	func init() {
+//	    events.Register[domainevents.ItemCreatedEvent](domainevents.TopicItemCreated, 3)
+//	    events.RegisterUpcaster(domainevents.TopicItemCreated, 1, itemCreatedV1ToV2)
+//	    events.RegisterUpcaster(domainevents.TopicItemCreated, 2, itemCreatedV2ToV3)
+//	}
+//
+// The topic must already be registered and fromVersion must be below its
+// current version. Like Register, it panics on duplicate or invalid registrations.
+func RegisterUpcaster(topic string, fromVersion int, fn Upcaster) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	et, ok := registry.byTopic[topic]
+	if !ok {
+		panic(fmt.Sprintf("events: upcaster for unregistered topic %q", topic))
+	}
+	if fn == nil || fromVersion < 1 || fromVersion >= et.version {
+		panic(fmt.Sprintf("events: invalid upcaster for %q: from version %d (current %d)", topic, fromVersion, et.version))
+	}
+	if _, ok := upcasters[topic][fromVersion]; ok {
+		panic(fmt.Sprintf("events: upcaster for %q from version %d already registered", topic, fromVersion))
+	}
+	if upcasters[topic] == nil {
+		upcasters[topic] = map[int]Upcaster{}
+	}
+	upcasters[topic][fromVersion] = fn
+}
+
+// Upcast upgrades payload from version to topic's current version by applying
+// each registered upcaster in turn. A payload already at the current version is
+// returned unchanged. Returns ErrUnknownEventVersion if version is newer than
+// the current one or a step in the chain has no upcaster.
+func Upcast(topic string, version int, payload []byte) ([]byte, error) {
+	registry.RLock()
+	et, ok := registry.byTopic[topic]
+	chain := upcasters[topic]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: topic %q", ErrUnregisteredEvent, topic)
+	}
+	if version < 1 || version > et.version {
+		return nil, fmt.Errorf("%w: %s version %d (current: %d)", ErrUnknownEventVersion, topic, version, et.version)
+	}
+
+	for v := version; v < et.version; v++ {
+		fn, ok := chain[v]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s has no upcaster from version %d", ErrUnknownEventVersion, topic, v)
+		}
+		next, err := fn(payload)
+		if err != nil {
+			return nil, fmt.Errorf("events: upcast %s from version %d: %w", topic, v, err)
+		}
+		payload = next
+	}
+	return payload, nil
+}
+
+// VersionsOf returns the versions of T's payload that may have been published
+// and that typed subscribers must therefore accept: every version from 1 to the
+// current one, in ascending order. A version is listed whether or not the chain
+// of upcasters from it to the current version is complete; Upcast reports the
+// gap.
+func VersionsOf[T any]() ([]int, error) {
+	et, err := lookup[T]()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, et.version)
+	for i := range versions {
+		versions[i] = i + 1
+	}
+	return versions, nil
+}
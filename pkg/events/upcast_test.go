@@ -0,0 +1,111 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+// testUpcastEvent is at version 3: v1 had "title", v2 renamed it to "name",
+// v3 added "tags".
+type testUpcastEvent struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func init() {
+	Register[testUpcastEvent]("test.upcast", 3)
+	RegisterUpcaster("test.upcast", 1, func(payload []byte) ([]byte, error) {
+		var v1 struct {
+			Title string `json:"title"`
+		}
+		if err := json.Unmarshal(payload, &v1); err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]any{"name": v1.Title})
+	})
+	RegisterUpcaster("test.upcast", 2, func(payload []byte) ([]byte, error) {
+		var v2 map[string]any
+		if err := json.Unmarshal(payload, &v2); err != nil {
+			return nil, err
+		}
+		v2["tags"] = []string{"migrated"}
+		return json.Marshal(v2)
+	})
+}
+
+// TestDecodeTyped_Upcasts verifies older payloads are chained up to the current version.
+func TestDecodeTyped_Upcasts(t *testing.T) {
+	tests := []struct {
+		version string
+		payload string
+		want    testUpcastEvent
+	}{
+		{"1", `{"title":"widget"}`, testUpcastEvent{Name: "widget", Tags: []string{"migrated"}}},
+		{"2", `{"name":"widget"}`, testUpcastEvent{Name: "widget", Tags: []string{"migrated"}}},
+		{"3", `{"name":"widget","tags":["new"]}`, testUpcastEvent{Name: "widget", Tags: []string{"new"}}},
+	}
+	for _, tt := range tests {
+		t.Run("v"+tt.version, func(t *testing.T) {
+			msg := message.NewMessage("id", []byte(tt.payload))
+			msg.Metadata.Set(MetaEventVersion, tt.version)
+
+			got, err := DecodeTyped[testUpcastEvent](msg)
+			if err != nil {
+				t.Fatalf("DecodeTyped: %v", err)
+			}
+			if got.Name != tt.want.Name || len(got.Tags) != 1 || got.Tags[0] != tt.want.Tags[0] {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestUpcast_RejectsNewerVersion verifies messages from a newer producer are not guessed at.
+func TestUpcast_RejectsNewerVersion(t *testing.T) {
+	if _, err := Upcast("test.upcast", 4, []byte(`{}`)); !errors.Is(err, ErrUnknownEventVersion) {
+		t.Errorf("expected ErrUnknownEventVersion, got %v", err)
+	}
+}
+
+// TestUpcast_ReportsBrokenChain verifies a missing step is an unknown version,
+// not a silently half-upgraded payload.
+func TestUpcast_ReportsBrokenChain(t *testing.T) {
+	type gapEvent struct{}
+	Register[gapEvent]("test.upcast.gap", 3)
+	RegisterUpcaster("test.upcast.gap", 2, func(payload []byte) ([]byte, error) { return payload, nil })
+
+	if _, err := Upcast("test.upcast.gap", 1, []byte(`{}`)); !errors.Is(err, ErrUnknownEventVersion) {
+		t.Errorf("expected ErrUnknownEventVersion, got %v", err)
+	}
+	versions, err := VersionsOf[gapEvent]()
+	if err != nil {
+		t.Fatalf("VersionsOf: %v", err)
+	}
+	if !slices.Equal(versions, []int{1, 2, 3}) {
+		t.Errorf("versions: got %v, want [1 2 3]", versions)
+	}
+}
+
+// TestRegisterUpcaster_PanicsOnInvalid verifies bad upcaster registrations are rejected.
+func TestRegisterUpcaster_PanicsOnInvalid(t *testing.T) {
+	noop := func(payload []byte) ([]byte, error) { return payload, nil }
+	for name, register := range map[string]func(){
+		"unregistered topic": func() { RegisterUpcaster("test.upcast.missing", 1, noop) },
+		"current version":    func() { RegisterUpcaster("test.upcast", 3, noop) },
+		"duplicate":          func() { RegisterUpcaster("test.upcast", 1, noop) },
+		"nil upcaster":       func() { RegisterUpcaster("test.upcast", 0, nil) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			register()
+		})
+	}
+}
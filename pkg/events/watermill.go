@@ -1,13 +1,49 @@
-// Package events provides a PostgreSQL-backed pub/sub EventBus built on Watermill.
+// Package events provides a PostgreSQL-backed pub/sub EventBus built on Watermill,
+// plus an in-memory MemoryBus for tests and local development. Both implement Bus.
+//
+// With cfg.EventTransport set to "redis", EventBus carries messages over Redis
+// Streams instead, with the same delivery semantics; stuck messages are claimed
+// from their consumer with XCLAIM. Transactional publishing (NewTxPublisher)
+// then writes to the outbox table, which the worker's relay publishes to Redis.
+// Dead letters, the inbox and scheduled messages stay in PostgreSQL; offset
+// management, Replay and lag metrics return ErrTransportUnsupported.
 //
 // Delivery semantics:
-//   - ConsumerGroup (default: <service>-consumer): messages are load-balanced across all
-//     instances in the group — only one instance processes each message. Use this for
-//     standard worker patterns.
-//   - Without ConsumerGroup: every subscriber receives every message (broadcast).
+//   - ConsumerGroup (default: <service>-consumer, or WithConsumerGroup): messages are
+//     load-balanced across all instances in the group — only one instance processes
+//     each message. Use this for standard worker patterns.
+//   - WithBroadcast: every subscriber receives every message published after it
+//     subscribed. Use this for cache invalidation and other per-instance signals.
+//
+// Within a subscription, messages with the same partition key (by default the
+// aggregate ID) are processed one at a time in publish order; WithConcurrency
+// processes different keys in parallel.
+//
+// Handlers should be idempotent. On failure the bus retries according to the
+// subscription's RetryPolicy (default: 3 attempts with exponential backoff);
+// errors marked with Permanent are not retried. A message that still fails is
+// moved to the topic's dead-letter topic (<topic>.dlq) together with failure
+// details and Acked, so a poison message never blocks the topic. See
+// ListDeadLetters, RequeueDeadLetter and PurgeDeadLetters for inspecting and
+// recovering dead-lettered messages.
+//
+// Publishing with cfg.EventCloudEvents wraps payloads in a CloudEvents 1.0
+// structured envelope for external consumers. Subscribers on every Bus unwrap
+// envelopes before the handler runs, so bare and enveloped messages can be
+// mixed on a topic.
 //
-// Handlers should be idempotent. On failure a message is Nacked and redelivered;
-// the bus retries up to 3 times with exponential backoff before giving up.
+// PublishAt and PublishAfter schedule messages for later delivery; the worker
+// publishes them when due with RunScheduler.
+//
+// To reprocess history, ConsumerGroups reports each group's offset and lag,
+// ResetOffset rewinds or fast-forwards a single group with its partition
+// groups, and Replay feeds a range of a topic to one handler without moving any
+// group's offset.
+//
+// Metrics go to the global OTel meter provider: published messages, handler
+// retries, failures and dead-lettered messages per topic and consumer group,
+// handler attempts and durations from the Metrics middleware, and consumer lag
+// and forwarder queue depth once RegisterLagMetrics is called.
 //
 // OTel context propagation: trace context is injected into message metadata on Publish
 // and extracted in Subscribe, enabling end-to-end distributed tracing across services.
@@ -18,44 +54,57 @@ import (
 	"database/sql"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
 	watermillsql "github.com/ThreeDotsLabs/watermill-sql/v3/pkg/sql"
 	"github.com/ThreeDotsLabs/watermill/components/forwarder"
 	"github.com/ThreeDotsLabs/watermill/message"
-	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/metric"
 
 	"github.com/ghuser/ghproject/pkg/config"
 	"github.com/ghuser/ghproject/pkg/logger"
+	"github.com/ghuser/ghproject/pkg/outbox"
 )
 
 const (
-	maxRetries      = 3
-	retryBaseDelay  = time.Second
-	shutdownTimeout = 30 * time.Second
-	forwarderTopic  = "_forwarder_queue" // internal outbox topic for the Forwarder daemon
+	shutdownTimeout  = 30 * time.Second
+	dropGroupTimeout = 5 * time.Second
+	forwarderTopic   = "_forwarder_queue" // internal outbox topic for the Forwarder daemon
 )
 
 // EventBus is a PostgreSQL-backed pub/sub EventBus built on Watermill's SQL transport.
 // It uses FOR UPDATE SKIP LOCKED under the hood for concurrent-safe delivery.
 type EventBus struct {
-	publisher    message.Publisher // either direct SQL publisher or forwarder-decorated
-	subscriber   *watermillsql.Subscriber
-	fwd          *forwarder.Forwarder // non-nil only when forwarder mode is enabled
-	db           *sql.DB
-	log          logger.Logger
-	wg           sync.WaitGroup
-	useForwarder bool
+	publisher     message.Publisher    // either direct SQL publisher, forwarder-decorated or Redis Streams
+	deadLetters   message.Publisher    // SQL publisher for dead letters; publisher unless redis is set
+	fwd           *forwarder.Forwarder // non-nil only when forwarder mode is enabled
+	redis         *redisStreams        // non-nil when cfg.EventTransport is "redis"
+	db            *sql.DB
+	log           logger.Logger
+	wg            sync.WaitGroup
+	useForwarder  bool
+	serviceName   string
+	consumerGroup string             // default group for subscriptions without WithConsumerGroup/WithBroadcast
+	cloudEvents   *CloudEventsConfig // non-nil when published messages are wrapped as CloudEvents
+	metrics       *busMetrics
+
+	mu          sync.Mutex
+	subscribers map[string]*watermillsql.Subscriber // by consumer group, created on first use
+	lagMetrics  metric.Registration                 // set by RegisterLagMetrics
+
+	scheduleReady atomic.Bool // scheduled messages table exists
+	topicsReady   sync.Map    // topic name → struct{}; tables created by initTopic
 }
 
 // NewEventBus opens a database connection from cfg.WatermillDatabaseURL and
 // initializes a Watermill SQL publisher and subscriber. Schema tables are
 // created automatically on first use.
 //
-// All instances with the same cfg.ServiceName share a ConsumerGroup, so each
-// message is processed by exactly one instance (load-balanced, not broadcast).
+// By default all instances with the same cfg.ServiceName share a ConsumerGroup,
+// so each message is processed by exactly one instance (load-balanced, not
+// broadcast). See WithConsumerGroup and WithBroadcast for the alternatives.
 func NewEventBus(cfg *config.Config, log logger.Logger) (*EventBus, error) {
 	return newEventBus(cfg, log, false)
 }
@@ -92,44 +141,205 @@ func newEventBus(cfg *config.Config, log logger.Logger, useForwarder bool) (*Eve
 		return nil, fmt.Errorf("events: new publisher: %w", err)
 	}
 
+	metrics, err := newBusMetrics()
+	if err != nil {
+		_ = pub.Close()
+		_ = db.Close()
+		return nil, err
+	}
+	var cloudEvents *CloudEventsConfig
+	if cfg.EventCloudEvents {
+		cloudEvents = &CloudEventsConfig{Source: "/" + cfg.ServiceName, DataSchemaBase: cfg.EventCloudEventsDataSchema}
+	}
+	// The CloudEvents envelope wraps the forwarder so it sees the target topic.
+	decorate := func(publisher message.Publisher) message.Publisher {
+		if cloudEvents != nil {
+			publisher = cloudEventsPublisher{next: publisher, cfg: *cloudEvents}
+		}
+		return meteredPublisher{next: publisher, metrics: metrics}
+	}
+
 	// In forwarder mode, wrap the publisher so messages are enveloped and
-	// routed through the forwarder queue instead of published directly.
-	var publisher message.Publisher = pub
-	if useForwarder {
-		publisher = forwarder.NewPublisher(pub, forwarder.PublisherConfig{
-			ForwarderTopic: forwarderTopic,
-		})
+	// routed through the forwarder queue instead of published directly. The
+	// Redis transport publishes straight to the stream: the forwarder queue
+	// would put every message back into PostgreSQL.
+	var (
+		publisher message.Publisher = pub
+		streams   *redisStreams
+	)
+	switch cfg.EventTransport {
+	case "", TransportPostgres:
+		if useForwarder {
+			publisher = forwarder.NewPublisher(pub, forwarder.PublisherConfig{
+				ForwarderTopic: forwarderTopic,
+			})
+		}
+	case TransportRedis:
+		if streams, err = newRedisStreams(cfg, log); err != nil {
+			_ = pub.Close()
+			_ = db.Close()
+			return nil, err
+		}
+		publisher = streams
+		useForwarder = false
+	default:
+		_ = pub.Close()
+		_ = db.Close()
+		return nil, fmt.Errorf("events: unknown transport %q", cfg.EventTransport)
+	}
+	publisher = decorate(publisher)
+	deadLetters := publisher
+	if streams != nil {
+		deadLetters = decorate(pub)
 	}
 
+	consumerGroup := cfg.ServiceName + "-consumer"
+	sub, err := newSubscriber(db, consumerGroup, wlog)
+	if err != nil {
+		_ = publisher.Close()
+		_ = pub.Close()
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &EventBus{
+		publisher:     publisher,
+		deadLetters:   deadLetters,
+		redis:         streams,
+		db:            db,
+		log:           log,
+		useForwarder:  useForwarder,
+		serviceName:   cfg.ServiceName,
+		consumerGroup: consumerGroup,
+		cloudEvents:   cloudEvents,
+		metrics:       metrics,
+		subscribers:   map[string]*watermillsql.Subscriber{consumerGroup: sub},
+	}, nil
+}
+
+// newSubscriber creates a Watermill SQL subscriber for consumerGroup.
+func newSubscriber(db *sql.DB, consumerGroup string, wlog watermill.LoggerAdapter) (*watermillsql.Subscriber, error) {
 	sub, err := watermillsql.NewSubscriber(
 		db,
 		watermillsql.SubscriberConfig{
 			SchemaAdapter:    watermillsql.DefaultPostgreSQLSchema{},
 			OffsetsAdapter:   watermillsql.DefaultPostgreSQLOffsetsAdapter{},
 			InitializeSchema: true,
-			ConsumerGroup:    cfg.ServiceName + "-consumer",
+			ConsumerGroup:    consumerGroup,
 		},
 		wlog,
 	)
 	if err != nil {
-		_ = pub.Close()
-		_ = db.Close()
-		return nil, fmt.Errorf("events: new subscriber: %w", err)
+		return nil, fmt.Errorf("events: new subscriber for %s: %w", consumerGroup, err)
 	}
+	return sub, nil
+}
 
-	return &EventBus{
-		publisher:    publisher,
-		subscriber:   sub,
-		db:           db,
-		log:          log,
-		useForwarder: useForwarder,
-	}, nil
+// subscriberFor returns the subscriber for consumerGroup, creating it on first use.
+func (q *EventBus) subscriberFor(consumerGroup string) (*watermillsql.Subscriber, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if sub, ok := q.subscribers[consumerGroup]; ok {
+		return sub, nil
+	}
+	sub, err := newSubscriber(q.db, consumerGroup, &slogAdapter{log: q.log})
+	if err != nil {
+		return nil, err
+	}
+	q.subscribers[consumerGroup] = sub
+	return sub, nil
+}
+
+// consumerGroupFor resolves the consumer group of a subscription. Broadcast
+// subscriptions get a unique group so no other subscriber shares their offsets.
+func (q *EventBus) consumerGroupFor(cfg subscribeConfig) string {
+	switch {
+	case cfg.broadcast:
+		return q.serviceName + "-broadcast-" + watermill.NewShortUUID()
+	case cfg.consumerGroup != "":
+		return cfg.consumerGroup
+	default:
+		return q.consumerGroup
+	}
+}
+
+// seekToEnd initializes topic's tables and sets consumerGroup's offset to the
+// latest message, so a new broadcast group does not replay the topic's history.
+// The statement mirrors how DefaultPostgreSQLOffsetsAdapter acks a message.
+func (q *EventBus) seekToEnd(ctx context.Context, sub *watermillsql.Subscriber, topic, consumerGroup string) error {
+	if err := sub.SubscribeInitialize(topic); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: initialize %s: %w", topic, err)
+	}
+	offsets := watermillsql.DefaultPostgreSQLOffsetsAdapter{}.MessagesOffsetsTable(topic)
+	messages := watermillsql.DefaultPostgreSQLSchema{}.MessagesTable(topic)
+	_, err := q.db.ExecContext(ctx,
+		`INSERT INTO `+offsets+` (consumer_group, offset_acked, last_processed_transaction_id)
+		 SELECT $1, "offset", transaction_id FROM `+messages+`
+		 ORDER BY transaction_id DESC, "offset" DESC LIMIT 1
+		 ON CONFLICT (consumer_group) DO NOTHING`,
+		consumerGroup,
+	)
+	if err != nil {
+		return fmt.Errorf("events: seek %s to end of %s: %w", consumerGroup, topic, err)
+	}
+	return nil
+}
+
+// seekToGroup initializes topic's tables and, unless consumerGroup already has
+// an offset, copies the offset of from, so a new partition group continues
+// where the unpartitioned subscription stopped.
+func (q *EventBus) seekToGroup(ctx context.Context, sub *watermillsql.Subscriber, topic, consumerGroup, from string) error {
+	if err := sub.SubscribeInitialize(topic); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: initialize %s: %w", topic, err)
+	}
+	offsets := watermillsql.DefaultPostgreSQLOffsetsAdapter{}.MessagesOffsetsTable(topic)
+	_, err := q.db.ExecContext(ctx,
+		`INSERT INTO `+offsets+` (consumer_group, offset_acked, last_processed_transaction_id)
+		 SELECT $1, offset_acked, last_processed_transaction_id FROM `+offsets+`
+		 WHERE consumer_group = $2
+		 ON CONFLICT (consumer_group) DO NOTHING`,
+		consumerGroup, from,
+	)
+	if err != nil {
+		return fmt.Errorf("events: seek %s to %s on %s: %w", consumerGroup, from, topic, err)
+	}
+	return nil
+}
+
+// initTopic creates the tables the publishers of NewTxPublisher write topic to:
+// the topic's own on the Postgres transport, the forwarder queue in forwarder
+// mode. The outbox table of the Redis transport is created by migrations.
+func (q *EventBus) initTopic(topic string) error {
+	if q.redis != nil {
+		return nil
+	}
+	if q.useForwarder {
+		topic = forwarderTopic
+	}
+	if _, ok := q.topicsReady.Load(topic); ok {
+		return nil
+	}
+	sub, err := q.subscriberFor(q.consumerGroup)
+	if err != nil {
+		return err
+	}
+	if err := sub.SubscribeInitialize(topic); err != nil { //nolint:contextcheck
+		return fmt.Errorf("events: initialize %s: %w", topic, err)
+	}
+	q.topicsReady.Store(topic, struct{}{})
+	return nil
 }
 
 // StartForwarder starts the background Forwarder daemon that reads messages from
 // the internal forwarder queue and publishes them to their target topics.
 // Must only be called once on an EventBus created with NewEventBusWithForwarder.
+// On the Redis transport, which does not use the forwarder, it does nothing.
 func (q *EventBus) StartForwarder(ctx context.Context) error {
+	if q.redis != nil {
+		q.log.InfoContext(ctx, "events: forwarder not used with the redis transport")
+		return nil
+	}
 	if !q.useForwarder {
 		return fmt.Errorf("events: StartForwarder called on non-forwarder EventBus")
 	}
@@ -140,18 +350,9 @@ func (q *EventBus) StartForwarder(ctx context.Context) error {
 	wlog := &slogAdapter{log: q.log}
 
 	// Separate subscriber for the forwarder to drain the outbox queue.
-	fwdSub, err := watermillsql.NewSubscriber(
-		q.db,
-		watermillsql.SubscriberConfig{
-			SchemaAdapter:    watermillsql.DefaultPostgreSQLSchema{},
-			OffsetsAdapter:   watermillsql.DefaultPostgreSQLOffsetsAdapter{},
-			InitializeSchema: true,
-			ConsumerGroup:    "forwarder-consumer",
-		},
-		wlog,
-	)
+	fwdSub, err := newSubscriber(q.db, "forwarder-consumer", wlog)
 	if err != nil {
-		return fmt.Errorf("events: new forwarder subscriber: %w", err)
+		return err
 	}
 
 	// Separate publisher for final delivery to target topics.
@@ -212,9 +413,16 @@ func (q *EventBus) DB() *sql.DB {
 //
 // If forwarder mode is enabled, messages are wrapped as forwarder envelopes so
 // the background Forwarder daemon picks them up and delivers them to the real topic.
+// With cfg.EventCloudEvents, messages are wrapped as CloudEvents as in Publish.
+//
+// On the Redis transport, messages are written to the outbox table within tx
+// instead (see package outbox); the worker's relay publishes them through Publish.
 //
 // AutoInitializeSchema is false — tables are guaranteed to exist after EventBus startup.
 func (q *EventBus) NewTxPublisher(tx *sql.Tx) (message.Publisher, error) {
+	if q.redis != nil {
+		return outbox.NewTxPublisher(context.Background(), tx), nil
+	}
 	wlog := &slogAdapter{log: q.log}
 	pub, err := watermillsql.NewPublisher(
 		tx,
@@ -227,25 +435,25 @@ func (q *EventBus) NewTxPublisher(tx *sql.Tx) (message.Publisher, error) {
 	if err != nil {
 		return nil, fmt.Errorf("events: new tx publisher: %w", err)
 	}
+	var publisher message.Publisher = pub
 	if q.useForwarder {
-		return forwarder.NewPublisher(pub, forwarder.PublisherConfig{
+		publisher = forwarder.NewPublisher(pub, forwarder.PublisherConfig{
 			ForwarderTopic: forwarderTopic,
-		}), nil
+		})
+	}
+	if q.cloudEvents != nil {
+		publisher = cloudEventsPublisher{next: publisher, cfg: *q.cloudEvents}
 	}
-	return pub, nil
+	return meteredPublisher{next: publisher, metrics: q.metrics}, nil
 }
 
 // Publish sends one or more messages to the given topic.
 // OTel trace context from ctx is injected into each message's metadata so
 // the receiving subscriber can restore the trace and continue the span tree.
+// With cfg.EventCloudEvents, each payload is wrapped in a CloudEvents 1.0
+// structured envelope (see ToCloudEvent).
 func (q *EventBus) Publish(ctx context.Context, topic string, msgs ...*message.Message) error {
-	carrier := propagation.MapCarrier{}
-	otel.GetTextMapPropagator().Inject(ctx, carrier)
-	for _, msg := range msgs {
-		for k, v := range carrier {
-			msg.Metadata.Set(k, v)
-		}
-	}
+	injectTrace(ctx, msgs)
 	if err := q.publisher.Publish(topic, msgs...); err != nil { //nolint:contextcheck
 		return fmt.Errorf("events: publish to %s: %w", topic, err)
 	}
@@ -258,102 +466,161 @@ func (q *EventBus) Publish(ctx context.Context, topic string, msgs ...*message.M
 //
 // Ack/Nack is managed by the bus:
 //   - handler returns nil   → Ack (message consumed)
-//   - handler returns error → retried up to 3× with exponential backoff (1s, 2s, 4s)
-//   - all retries exhausted → Nack + error forwarded to the returned channel
+//   - handler returns error → retried per the RetryPolicy (default: 3 attempts, 1s then 2s apart)
+//   - attempts exhausted or Permanent error → published to <topic>.dlq, Ack, error forwarded to the returned channel
+//   - dead-lettering fails  → Nack (redelivered later) + error forwarded to the returned channel
 //
 // The returned error channel is buffered (capacity 100). Callers must drain it:
 //
 //	errCh, err := bus.Subscribe(ctx, topic, handler)
 //	go func() { for err := range errCh { log.ErrorContext(ctx, "subscriber error", "error", err) } }()
 //
+// Pass WithInbox to skip events this consumer group has already processed,
+// and WithConsumerGroup or WithBroadcast to change how messages are shared
+// between instances. WithRetryPolicy, WithAttemptTimeout and WithMiddleware
+// control how each attempt runs, and WithConcurrency processes messages with
+// different partition keys in parallel.
+//
 // All in-flight handlers complete before Close() returns.
-func (q *EventBus) Subscribe(ctx context.Context, topic string, handler func(context.Context, *message.Message) error) (<-chan error, error) {
-	ch, err := q.subscriber.Subscribe(ctx, topic)
+func (q *EventBus) Subscribe(
+	ctx context.Context,
+	topic string,
+	handler func(context.Context, *message.Message) error,
+	opts ...SubscribeOption,
+) (<-chan error, error) {
+	cfg := newSubscribeConfig(opts)
+	name := cfg.handlerName
+	if name == "" {
+		name = handlerName(handler)
+	}
+	group := q.consumerGroupFor(cfg)
+	if cfg.inbox {
+		if err := q.initInbox(ctx); err != nil {
+			return nil, err
+		}
+		handler = q.withInbox(topic, group, handler)
+	}
+
+	return subscribePartitioned(ctx, q.log, topic, cfg,
+		func(ctx context.Context, part partition) (<-chan *message.Message, error) {
+			return q.subscribePartition(ctx, topic, group, part, cfg.broadcast)
+		},
+		func(ctx context.Context, msgs <-chan *message.Message, part partition) <-chan error {
+			return deliver(ctx, &q.wg, q.log, q.deadLetters, q.metrics, topic, partitionGroup(group, part), name, msgs, handler, cfg, part)
+		},
+	)
+}
+
+// subscribePartition subscribes to topic in part's consumer group within group.
+// A new broadcast group starts at the end of the topic and a new partition
+// group at the offset of group.
+func (q *EventBus) subscribePartition(
+	ctx context.Context,
+	topic, group string,
+	part partition,
+	broadcast bool,
+) (<-chan *message.Message, error) {
+	if q.redis != nil {
+		return q.subscribeRedis(ctx, topic, group, part, broadcast)
+	}
+	partGroup := partitionGroup(group, part)
+	sub, err := q.subscriberFor(partGroup)
 	if err != nil {
-		return nil, fmt.Errorf("events: subscribe to %s: %w", topic, err)
+		return nil, err
+	}
+	switch {
+	case broadcast:
+		err = q.seekToEnd(ctx, sub, topic, partGroup)
+	case partGroup != group:
+		err = q.seekToGroup(ctx, sub, topic, partGroup, group)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	errCh := make(chan error, 100)
-	propagator := otel.GetTextMapPropagator()
+	ch, err := sub.Subscribe(ctx, topic)
+	if err != nil {
+		return nil, fmt.Errorf("events: subscribe to %s: %w", topic, err)
+	}
+	if broadcast {
+		return q.dropGroupWhenDone(topic, partGroup, ch), nil
+	}
+	return ch, nil
+}
 
+// dropGroupWhenDone forwards msgs until the subscription ends, when its
+// context is cancelled or the bus closes, then closes consumerGroup's
+// subscriber and deletes its offset on topic. Broadcast groups belong to one
+// subscription, so this keeps them from piling up in the offsets tables as
+// processes restart, as the Redis transport destroys its broadcast groups.
+// A process that exits without Close leaves its groups behind; they are named
+// "<service>-broadcast-<id>" and can be deleted from the offsets tables.
+func (q *EventBus) dropGroupWhenDone(topic, consumerGroup string, msgs <-chan *message.Message) <-chan *message.Message {
+	out := make(chan *message.Message)
 	q.wg.Add(1)
 	go func() {
 		defer q.wg.Done()
-		defer close(errCh)
-
-		for msg := range ch {
-			// Restore the publisher's trace context from message metadata.
-			carrier := propagation.MapCarrier{}
-			for k, v := range msg.Metadata {
-				carrier[k] = v
-			}
-			msgCtx := propagator.Extract(ctx, carrier)
-
-			if err := retryWithBackoff(msgCtx, msg, handler, maxRetries, retryBaseDelay, q.log); err != nil {
-				msg.Nack()
-				select {
-				case errCh <- err:
-				default:
-					q.log.ErrorContext(msgCtx, "events: error channel full, dropping error",
-						"error", err, "topic", topic)
-				}
-			} else {
-				msg.Ack()
-			}
+		defer close(out)
+		for msg := range msgs {
+			out <- msg
 		}
-	}()
-
-	return errCh, nil
-}
 
-// retryWithBackoff calls handler up to maxRetries times with exponential backoff.
-// Returns nil on first success; returns the last error after all retries exhaust.
-func retryWithBackoff(
-	ctx context.Context,
-	msg *message.Message,
-	handler func(context.Context, *message.Message) error,
-	maxRetries int,
-	baseDelay time.Duration,
-	log logger.Logger,
-) error {
-	delay := baseDelay
-	var err error
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		if err = handler(ctx, msg); err == nil {
-			return nil
+		q.mu.Lock()
+		sub := q.subscribers[consumerGroup]
+		delete(q.subscribers, consumerGroup)
+		if sub != nil {
+			_ = sub.Close() // already closed when the bus is closing
 		}
-		if attempt < maxRetries {
-			log.WarnContext(ctx, "events: handler failed, retrying",
-				"attempt", attempt,
-				"max_retries", maxRetries,
-				"next_delay", delay,
-				"error", err,
-			)
-			select {
-			case <-ctx.Done():
-				return ctx.Err()
-			case <-time.After(delay):
-			}
-			delay *= 2
+		q.mu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), dropGroupTimeout)
+		defer cancel()
+		offsets := watermillsql.DefaultPostgreSQLOffsetsAdapter{}.MessagesOffsetsTable(topic)
+		if _, err := q.db.ExecContext(ctx,
+			`DELETE FROM `+offsets+` WHERE consumer_group = $1`, consumerGroup,
+		); err != nil {
+			q.log.Warn("events: delete broadcast consumer group",
+				"topic", topic, "consumer_group", consumerGroup, "error", err)
 		}
-	}
-	return fmt.Errorf("events: handler failed after %d retries: %w", maxRetries, err)
+	}()
+	return out
 }
 
-// Ping checks the EventBus database connection health.
+// Ping checks the EventBus database connection health, and the Redis
+// connection on the Redis transport.
 func (q *EventBus) Ping(ctx context.Context) error {
 	if err := q.db.PingContext(ctx); err != nil {
 		return fmt.Errorf("events: ping db: %w", err)
 	}
+	if q.redis != nil {
+		if err := q.redis.client.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("events: ping redis: %w", err)
+		}
+	}
 	return nil
 }
 
 // Close gracefully shuts down the EventBus.
-// Shutdown order: stop subscriber → stop forwarder (if running) → wait for
-// in-flight handlers (30 s max) → close publisher → close database connection.
+// Shutdown order: stop subscribers → stop forwarder (if running) → wait for
+// in-flight handlers (30 s max) → close publisher (and the Redis connection)
+// → close database connection.
 func (q *EventBus) Close() error {
-	if err := q.subscriber.Close(); err != nil {
-		return fmt.Errorf("events: close subscriber: %w", err)
+	q.mu.Lock()
+	if q.lagMetrics != nil {
+		if err := q.lagMetrics.Unregister(); err != nil {
+			q.log.Warn("events: unregister lag metrics", "error", err)
+		}
+		q.lagMetrics = nil
+	}
+	for group, sub := range q.subscribers {
+		if err := sub.Close(); err != nil {
+			q.mu.Unlock()
+			return fmt.Errorf("events: close subscriber %s: %w", group, err)
+		}
+	}
+	q.mu.Unlock()
+	if q.redis != nil {
+		q.redis.stop()
 	}
 
 	if q.fwd != nil {
@@ -378,6 +645,11 @@ func (q *EventBus) Close() error {
 	if err := q.publisher.Close(); err != nil {
 		return fmt.Errorf("events: close publisher: %w", err)
 	}
+	if q.redis != nil {
+		if err := q.deadLetters.Close(); err != nil {
+			return fmt.Errorf("events: close dead-letter publisher: %w", err)
+		}
+	}
 	return q.db.Close()
 }
 
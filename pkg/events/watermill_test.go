@@ -3,6 +3,7 @@ package events
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -27,6 +28,10 @@ func nopLogger() logger.Logger {
 	return logger.New(&config.Config{LogLevel: "error"})
 }
 
+func testRetryPolicy(baseDelay time.Duration) RetryPolicy {
+	return RetryPolicy{BaseDelay: baseDelay}.withDefaults()
+}
+
 // TestRetryWithBackoff_SuccessOnFirstAttempt verifies no retry occurs on success.
 func TestRetryWithBackoff_SuccessOnFirstAttempt(t *testing.T) {
 	calls := 0
@@ -35,7 +40,7 @@ func TestRetryWithBackoff_SuccessOnFirstAttempt(t *testing.T) {
 		return nil
 	}
 	msg := message.NewMessage("id", nil)
-	err := retryWithBackoff(context.Background(), msg, handler, maxRetries, time.Millisecond, nopLogger())
+	err := retryWithBackoff(context.Background(), msg, handler, testRetryPolicy(time.Millisecond), nopLogger())
 	if err != nil {
 		t.Fatalf("expected nil, got %v", err)
 	}
@@ -55,7 +60,7 @@ func TestRetryWithBackoff_SuccessAfterRetries(t *testing.T) {
 		return nil
 	}
 	msg := message.NewMessage("id", nil)
-	err := retryWithBackoff(context.Background(), msg, handler, maxRetries, time.Millisecond, nopLogger())
+	err := retryWithBackoff(context.Background(), msg, handler, testRetryPolicy(time.Millisecond), nopLogger())
 	if err != nil {
 		t.Fatalf("expected nil after eventual success, got %v", err)
 	}
@@ -72,12 +77,12 @@ func TestRetryWithBackoff_ExhaustsRetries(t *testing.T) {
 		return errors.New("permanent error")
 	}
 	msg := message.NewMessage("id", nil)
-	err := retryWithBackoff(context.Background(), msg, handler, maxRetries, time.Millisecond, nopLogger())
+	err := retryWithBackoff(context.Background(), msg, handler, testRetryPolicy(time.Millisecond), nopLogger())
 	if err == nil {
 		t.Fatal("expected error after exhausted retries")
 	}
-	if calls != maxRetries {
-		t.Errorf("expected %d calls, got %d", maxRetries, calls)
+	if calls != DefaultMaxAttempts {
+		t.Errorf("expected %d calls, got %d", DefaultMaxAttempts, calls)
 	}
 }
 
@@ -92,7 +97,7 @@ func TestRetryWithBackoff_ContextCancelled(t *testing.T) {
 		return errors.New("error")
 	}
 	msg := message.NewMessage("id", nil)
-	err := retryWithBackoff(ctx, msg, handler, maxRetries, time.Second, nopLogger())
+	err := retryWithBackoff(ctx, msg, handler, testRetryPolicy(time.Second), nopLogger())
 	if err == nil {
 		t.Fatal("expected error from canceled context")
 	}
@@ -160,3 +165,50 @@ func TestOTelPropagation_InjectExtract(t *testing.T) {
 		t.Errorf("trace ID mismatch: want %s, got %s", wantTraceID, gotSpan.SpanContext().TraceID())
 	}
 }
+
+// TestConsumerGroupFor verifies default, custom and broadcast group resolution.
+func TestConsumerGroupFor(t *testing.T) {
+	bus := &EventBus{serviceName: "svc", consumerGroup: "svc-consumer"}
+
+	if got := bus.consumerGroupFor(newSubscribeConfig(nil)); got != "svc-consumer" {
+		t.Errorf("default: got %q, want %q", got, "svc-consumer")
+	}
+	if got := bus.consumerGroupFor(newSubscribeConfig([]SubscribeOption{WithConsumerGroup("audit")})); got != "audit" {
+		t.Errorf("custom: got %q, want %q", got, "audit")
+	}
+
+	broadcast := []SubscribeOption{WithConsumerGroup("audit"), WithBroadcast()}
+	first := bus.consumerGroupFor(newSubscribeConfig(broadcast))
+	second := bus.consumerGroupFor(newSubscribeConfig(broadcast))
+	if !strings.HasPrefix(first, "svc-broadcast-") {
+		t.Errorf("broadcast: got %q, want prefix %q", first, "svc-broadcast-")
+	}
+	if first == second {
+		t.Errorf("broadcast: expected unique groups, got %q twice", first)
+	}
+}
+
+// TestDropGroupWhenDone verifies a broadcast subscription forwards its
+// messages and deletes its consumer group once it ends.
+func TestDropGroupWhenDone(t *testing.T) {
+	q, f := newFakeBus(t)
+	msgs := make(chan *message.Message, 1)
+	out := q.dropGroupWhenDone("cache.invalidate", "svc-broadcast-1", msgs)
+
+	msgs <- message.NewMessage("m1", nil)
+	if msg := <-out; msg.UUID != "m1" {
+		t.Errorf("forwarded %s, want m1", msg.UUID)
+	}
+	if dropped := f.snapshot().droppedGroups; len(dropped) != 0 {
+		t.Fatalf("group dropped while subscribed: %v", dropped)
+	}
+
+	close(msgs)
+	if _, ok := <-out; ok {
+		t.Fatal("expected the forwarded channel to close")
+	}
+	q.wg.Wait()
+	if dropped := f.snapshot().droppedGroups; len(dropped) != 1 || dropped[0] != "cache.invalidate/svc-broadcast-1" {
+		t.Errorf("dropped groups: got %v, want [cache.invalidate/svc-broadcast-1]", dropped)
+	}
+}
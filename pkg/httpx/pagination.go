@@ -0,0 +1,151 @@
+package httpx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pagination defaults shared by every list endpoint.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var (
+	// ErrInvalidPageParams is returned by ParsePageParams for malformed query parameters.
+	// Handlers should respond with 400 Bad Request.
+	ErrInvalidPageParams = errors.New("invalid pagination parameters")
+
+	// ErrInvalidCursor is returned when a cursor is malformed or its signature does not verify.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Cursor is the decoded form of an opaque keyset pagination cursor.
+// It identifies the last row of a page in (created_at DESC, id DESC) order.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// CursorCodec turns Cursors into opaque, tamper-proof strings and back.
+// Format: base64url(JSON payload) "." base64url(HMAC-SHA256(payload)).
+// Clients must treat cursors as opaque; the signature prevents them from
+// crafting positions (e.g. to probe another tenant's timeline).
+type CursorCodec struct {
+	key []byte
+}
+
+// NewCursorCodec returns a CursorCodec that signs cursors with key.
+// Use at least 32 random bytes in production.
+func NewCursorCodec(key []byte) *CursorCodec {
+	return &CursorCodec{key: key}
+}
+
+// Encode serializes and signs cur.
+func (c *CursorCodec) Encode(cur Cursor) string {
+	payload, _ := json.Marshal(cur) // fixed struct of time + uuid; cannot fail
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload))
+}
+
+// Decode verifies and parses a cursor produced by Encode.
+// Returns ErrInvalidCursor if the string is malformed or the signature does not match.
+func (c *CursorCodec) Decode(s string) (Cursor, error) {
+	payloadPart, sigPart, ok := strings.Cut(s, ".")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigPart)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if !hmac.Equal(sig, c.sign(payload)) {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cur Cursor
+	if err := json.Unmarshal(payload, &cur); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return cur, nil
+}
+
+func (c *CursorCodec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// PageParams are the pagination query parameters of a list request.
+// Cursor is non-nil in keyset mode; otherwise Offset applies.
+type PageParams struct {
+	Limit        int
+	Offset       int
+	Cursor       *Cursor
+	IncludeTotal bool
+}
+
+// ParsePageParams reads limit, offset, cursor and include_total from the query string:
+//
+//	?limit=20&cursor=<opaque>           keyset mode (preferred)
+//	?limit=20&offset=40                 offset mode
+//	?include_total=true                 also count all matching rows
+//
+// limit defaults to DefaultPageLimit and must be within [1, MaxPageLimit].
+// cursor and a non-zero offset are mutually exclusive.
+// All failures wrap ErrInvalidPageParams.
+func ParsePageParams(r *http.Request, codec *CursorCodec) (PageParams, error) {
+	p := PageParams{Limit: DefaultPageLimit}
+	q := r.URL.Query()
+
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 1 || limit > MaxPageLimit {
+			return p, fmt.Errorf("%w: limit must be an integer between 1 and %d", ErrInvalidPageParams, MaxPageLimit)
+		}
+		p.Limit = limit
+	}
+
+	if s := q.Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return p, fmt.Errorf("%w: offset must be a non-negative integer", ErrInvalidPageParams)
+		}
+		p.Offset = offset
+	}
+
+	if s := q.Get("cursor"); s != "" {
+		if p.Offset != 0 {
+			return p, fmt.Errorf("%w: cursor and offset cannot be combined", ErrInvalidPageParams)
+		}
+		cur, err := codec.Decode(s)
+		if err != nil {
+			return p, fmt.Errorf("%w: %w", ErrInvalidPageParams, err)
+		}
+		p.Cursor = &cur
+	}
+
+	if s := q.Get("include_total"); s != "" {
+		include, err := strconv.ParseBool(s)
+		if err != nil {
+			return p, fmt.Errorf("%w: include_total must be a boolean", ErrInvalidPageParams)
+		}
+		p.IncludeTotal = include
+	}
+
+	return p, nil
+}
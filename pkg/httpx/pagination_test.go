@@ -0,0 +1,116 @@
+package httpx_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ghuser/ghproject/pkg/httpx"
+)
+
+func newTestCodec() *httpx.CursorCodec {
+	return httpx.NewCursorCodec([]byte("test-cursor-key-must-be-32-bytes"))
+}
+
+func TestCursorCodec_RoundTrip(t *testing.T) {
+	codec := newTestCodec()
+	want := httpx.Cursor{
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := codec.Decode(codec.Encode(want))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("round trip mismatch: got %+v, want %+v", got, want)
+	}
+}
+
+func TestCursorCodec_RejectsTampered(t *testing.T) {
+	codec := newTestCodec()
+	s := codec.Encode(httpx.Cursor{CreatedAt: time.Now().UTC(), ID: uuid.New()})
+
+	payload, sig, _ := strings.Cut(s, ".")
+	forged := newTestCodec().Encode(httpx.Cursor{CreatedAt: time.Now().UTC(), ID: uuid.New()})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	cases := map[string]string{
+		"swapped payload": forgedPayload + "." + sig,
+		"missing sig":     payload,
+		"bad base64":      "!!!." + sig,
+		"empty":           "",
+	}
+	for name, in := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := codec.Decode(in); !errors.Is(err, httpx.ErrInvalidCursor) {
+				t.Errorf("expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCursorCodec_RejectsOtherKey(t *testing.T) {
+	s := newTestCodec().Encode(httpx.Cursor{CreatedAt: time.Now().UTC(), ID: uuid.New()})
+	other := httpx.NewCursorCodec([]byte("another-cursor-key-of-32-bytes!!"))
+	if _, err := other.Decode(s); !errors.Is(err, httpx.ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestParsePageParams_Defaults(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/item", http.NoBody)
+	p, err := httpx.ParsePageParams(r, newTestCodec())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Limit != httpx.DefaultPageLimit || p.Offset != 0 || p.Cursor != nil || p.IncludeTotal {
+		t.Errorf("unexpected defaults: %+v", p)
+	}
+}
+
+func TestParsePageParams_Cursor(t *testing.T) {
+	codec := newTestCodec()
+	want := httpx.Cursor{CreatedAt: time.Now().UTC().Truncate(time.Microsecond), ID: uuid.New()}
+	r := httptest.NewRequest(http.MethodGet, "/item?limit=5&include_total=true&cursor="+codec.Encode(want), http.NoBody)
+
+	p, err := httpx.ParsePageParams(r, codec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Limit != 5 || !p.IncludeTotal {
+		t.Errorf("unexpected params: %+v", p)
+	}
+	if p.Cursor == nil || p.Cursor.ID != want.ID || !p.Cursor.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("cursor mismatch: got %+v, want %+v", p.Cursor, want)
+	}
+}
+
+func TestParsePageParams_Invalid(t *testing.T) {
+	codec := newTestCodec()
+	valid := codec.Encode(httpx.Cursor{CreatedAt: time.Now().UTC(), ID: uuid.New()})
+
+	cases := map[string]string{
+		"limit zero":        "limit=0",
+		"limit too large":   "limit=101",
+		"limit not int":     "limit=abc",
+		"negative offset":   "offset=-1",
+		"bad cursor":        "cursor=garbage",
+		"cursor and offset": "offset=10&cursor=" + valid,
+		"bad include_total": "include_total=maybe",
+	}
+	for name, query := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/item?"+query, http.NoBody)
+			if _, err := httpx.ParsePageParams(r, codec); !errors.Is(err, httpx.ErrInvalidPageParams) {
+				t.Errorf("expected ErrInvalidPageParams, got %v", err)
+			}
+		})
+	}
+}
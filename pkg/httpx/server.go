@@ -80,7 +80,7 @@ func CORSMiddleware(allowedOrigins string) func(http.Handler) http.Handler {
 	origins := parseOrigins(allowedOrigins)
 	return cors.Handler(cors.Options{
 		AllowedOrigins:   origins,
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Request-Id"},
 		ExposedHeaders:   []string{"Link", "X-Request-Id"},
 		AllowCredentials: false,
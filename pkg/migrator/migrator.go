@@ -11,6 +11,12 @@ import (
 
 // RunMigrations runs all pending goose migrations from the embedded FS against dbUrl.
 func RunMigrations(dbUrl string, files fs.FS) error {
+	return RunMigrationsWithTable(dbUrl, goose.DefaultTablename, files)
+}
+
+// RunMigrationsWithTable is RunMigrations with a custom goose version table, for
+// migration sets that are versioned independently of the service migrations.
+func RunMigrationsWithTable(dbUrl, table string, files fs.FS) error {
 	db, err := sql.Open("pgx", dbUrl)
 	if err != nil {
 		panic(fmt.Errorf("failed to open database: %w", err))
@@ -18,6 +24,7 @@ func RunMigrations(dbUrl string, files fs.FS) error {
 	defer db.Close() //nolint:errcheck
 
 	goose.SetBaseFS(files)
+	goose.SetTableName(table)
 
 	if err := goose.SetDialect("postgres"); err != nil {
 		return fmt.Errorf("failed to set goose dialect: %w", err)
@@ -0,0 +1,91 @@
+// Package outbox implements the transactional outbox pattern on a plain
+// PostgreSQL table (outbox.messages), independently of Watermill's forwarder.
+//
+// Writers insert messages in the same transaction as the business change:
+//
+//	err := db.WithTx(ctx, func(tx *sql.Tx) error {
+//	    if err := repo.save(ctx, tx, item); err != nil {
+//	        return err
+//	    }
+//	    return events.PublishTyped(ctx, outbox.NewTxPublisher(ctx, tx), evt)
+//	})
+//
+// A Relay (normally run by the worker) claims unpublished rows, publishes them
+// to the EventBus and marks them published. Delivery is at-least-once: a crash
+// between publishing and marking republishes the batch, so consumers should
+// deduplicate (see events.WithInbox).
+//
+// The table is created by migrations/outbox.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// table is the outbox table created by migrations/outbox.
+const table = "outbox.messages"
+
+// ErrPublisherClosed is returned by a tx publisher used after Close.
+var ErrPublisherClosed = errors.New("outbox: publisher closed")
+
+// Write inserts msgs for topic into the outbox within tx. The OTel trace
+// context from ctx is stored in each message's metadata so consumers continue
+// the writer's trace, as with EventBus.Publish.
+func Write(ctx context.Context, tx *sql.Tx, topic string, msgs ...*message.Message) error {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	for _, msg := range msgs {
+		for k, v := range carrier {
+			if msg.Metadata.Get(k) == "" {
+				msg.Metadata.Set(k, v)
+			}
+		}
+		metadata, err := json.Marshal(msg.Metadata)
+		if err != nil {
+			return fmt.Errorf("outbox: marshal metadata for %s: %w", msg.UUID, err)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO `+table+` (uuid, topic, payload, metadata) VALUES ($1, $2, $3, $4)`,
+			msg.UUID, topic, []byte(msg.Payload), metadata,
+		); err != nil {
+			return fmt.Errorf("outbox: write %s to %s: %w", msg.UUID, topic, err)
+		}
+	}
+	return nil
+}
+
+// txPublisher adapts Write to Watermill's message.Publisher.
+type txPublisher struct {
+	ctx    context.Context
+	tx     *sql.Tx
+	closed bool
+}
+
+// NewTxPublisher returns a message.Publisher that writes to the outbox within tx,
+// so helpers such as events.PublishTyped can target the outbox instead of the
+// EventBus. ctx supplies cancellation and trace context for every Publish.
+func NewTxPublisher(ctx context.Context, tx *sql.Tx) message.Publisher {
+	return &txPublisher{ctx: ctx, tx: tx}
+}
+
+func (p *txPublisher) Publish(topic string, msgs ...*message.Message) error {
+	if p.closed {
+		return ErrPublisherClosed
+	}
+	return Write(p.ctx, p.tx, topic, msgs...)
+}
+
+// Close marks the publisher closed. It does not end the transaction.
+func (p *txPublisher) Close() error {
+	p.closed = true
+	return nil
+}
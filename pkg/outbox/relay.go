@@ -0,0 +1,382 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+const meterName = "github.com/ghuser/ghproject/pkg/outbox"
+
+// Relay defaults, applied by NewRelay to zero RelayConfig fields.
+const (
+	DefaultBatchSize    = 100
+	DefaultPollInterval = time.Second
+	DefaultMaxBackoff   = 30 * time.Second
+	DefaultMaxAttempts  = 10
+)
+
+// EventPublisher is the destination of relayed messages. *events.EventBus satisfies it.
+type EventPublisher interface {
+	Publish(ctx context.Context, topic string, msgs ...*message.Message) error
+}
+
+// RelayConfig tunes a Relay. Zero values fall back to the defaults above.
+type RelayConfig struct {
+	BatchSize    int           // rows claimed per transaction
+	PollInterval time.Duration // wait between polls when the outbox is drained
+	MaxBackoff   time.Duration // upper bound of the exponential backoff after failures
+	MaxAttempts  int           // failed publishes after which a row is marked failed and skipped
+	Retention    time.Duration // published rows older than this are deleted; 0 keeps them forever
+}
+
+// Stats is a point-in-time view of the outbox backlog.
+type Stats struct {
+	Pending int64         // unpublished rows still being relayed
+	Lag     time.Duration // age of the oldest pending row; 0 when Pending is 0
+	Failed  int64         // rows marked failed after MaxAttempts publish failures
+}
+
+// Relay moves messages from the outbox table to an EventPublisher.
+// Several relays may run concurrently (e.g. one per worker replica): batches
+// are claimed with FOR UPDATE SKIP LOCKED, so no row is published by two relays
+// at once. Within a relay, rows are published in insertion order and a failed
+// row stops its batch, so later rows are not published ahead of it. After
+// MaxAttempts failures the row is marked failed (failed_at) and skipped, so a
+// poison message cannot block the outbox; later rows then overtake it.
+type Relay struct {
+	db      *sql.DB
+	pub     EventPublisher
+	log     logger.Logger
+	cfg     RelayConfig
+	metrics *relayMetrics
+}
+
+// NewRelay creates a Relay and registers its OTel metrics:
+//   - outbox.relay.published / outbox.relay.failures: messages per topic (throughput)
+//   - outbox.relay.failed: messages per topic marked failed after MaxAttempts
+//   - outbox.relay.delay: seconds from write to publish
+//   - outbox.pending / outbox.lag: backlog size and age of the oldest pending row
+//   - outbox.failed: rows marked failed and awaiting an operator
+//
+// The gauges are observed until Run returns.
+func NewRelay(db *sql.DB, pub EventPublisher, log logger.Logger, cfg RelayConfig) (*Relay, error) {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
+	}
+	if cfg.MaxBackoff < cfg.PollInterval {
+		cfg.MaxBackoff = max(DefaultMaxBackoff, cfg.PollInterval)
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+
+	r := &Relay{db: db, pub: pub, log: log, cfg: cfg}
+	m, err := newRelayMetrics(otel.Meter(meterName), r)
+	if err != nil {
+		return nil, err
+	}
+	r.metrics = m
+	return r, nil
+}
+
+// Run relays batches until ctx is cancelled. A full batch is followed
+// immediately by the next one; an empty or partial batch waits PollInterval.
+// Failures back off exponentially up to MaxBackoff and are retried. On return
+// the relay's gauges are unregistered, so a stopped relay no longer queries
+// the outbox on each metrics collection.
+func (r *Relay) Run(ctx context.Context) {
+	r.log.InfoContext(ctx, "outbox: relay started",
+		"batch_size", r.cfg.BatchSize, "poll_interval", r.cfg.PollInterval)
+	defer func() {
+		if err := r.metrics.gauges.Unregister(); err != nil {
+			r.log.WarnContext(ctx, "outbox: unregister gauges failed", "error", err)
+		}
+	}()
+
+	var cleanup <-chan time.Time
+	if r.cfg.Retention > 0 {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		cleanup = ticker.C
+	}
+
+	delay := r.cfg.PollInterval
+	for {
+		n, err := r.RelayBatch(ctx)
+		switch {
+		case ctx.Err() != nil:
+			r.log.InfoContext(ctx, "outbox: relay stopped")
+			return
+		case err != nil:
+			delay = nextBackoff(delay, r.cfg.MaxBackoff)
+			r.log.ErrorContext(ctx, "outbox: relay batch failed",
+				"published", n, "retry_in", delay, "error", err)
+		case n == r.cfg.BatchSize:
+			delay = r.cfg.PollInterval
+			continue
+		default:
+			delay = r.cfg.PollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			r.log.InfoContext(ctx, "outbox: relay stopped")
+			return
+		case <-cleanup:
+			if n, err := r.Cleanup(ctx, r.cfg.Retention); err != nil {
+				r.log.ErrorContext(ctx, "outbox: cleanup failed", "error", err)
+			} else if n > 0 {
+				r.log.InfoContext(ctx, "outbox: cleaned up", "deleted", n, "retention", r.cfg.Retention)
+			}
+		case <-time.After(delay):
+		}
+	}
+}
+
+// nextBackoff doubles delay, capped at maxDelay.
+func nextBackoff(delay, maxDelay time.Duration) time.Duration {
+	return min(delay*2, maxDelay)
+}
+
+// claimed is an outbox row locked by the current batch.
+type claimed struct {
+	id        int64
+	uuid      string
+	topic     string
+	payload   []byte
+	metadata  map[string]string
+	createdAt time.Time
+}
+
+// RelayBatch claims up to BatchSize pending rows, publishes them in order and
+// marks the published ones in the same transaction. On a publish failure the
+// failing row's attempts and last_error are recorded, the rows before it are
+// still marked published, and the error is returned with their count. The
+// failure that brings a row to MaxAttempts also marks it failed, so later
+// batches skip it.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: begin relay tx: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	batch, err := claimBatch(ctx, tx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(batch))
+	var pubErr error
+	for _, row := range batch {
+		msg := message.NewMessage(row.uuid, row.payload)
+		for k, v := range row.metadata {
+			msg.Metadata.Set(k, v)
+		}
+		topic := metric.WithAttributes(attribute.String("topic", row.topic))
+
+		if err := r.pub.Publish(ctx, row.topic, msg); err != nil {
+			r.metrics.failures.Add(ctx, 1, topic)
+			pubErr = fmt.Errorf("outbox: publish %s to %s: %w", row.uuid, row.topic, err)
+			break
+		}
+		ids = append(ids, row.id)
+		r.metrics.published.Add(ctx, 1, topic)
+		r.metrics.delay.Record(ctx, time.Since(row.createdAt).Seconds(), topic)
+	}
+
+	if pubErr != nil {
+		failed := batch[len(ids)]
+		var (
+			attempts int
+			gaveUp   bool
+		)
+		if err := tx.QueryRowContext(ctx, `
+			UPDATE `+table+`
+			SET attempts = attempts + 1, last_error = $2,
+				failed_at = CASE WHEN attempts + 1 >= $3 THEN now() END
+			WHERE id = $1
+			RETURNING attempts, failed_at IS NOT NULL`,
+			failed.id, pubErr.Error(), r.cfg.MaxAttempts,
+		).Scan(&attempts, &gaveUp); err != nil {
+			return 0, fmt.Errorf("outbox: record failure of %s: %w", failed.uuid, err)
+		}
+		if gaveUp {
+			r.metrics.failed.Add(ctx, 1, metric.WithAttributes(attribute.String("topic", failed.topic)))
+			r.log.ErrorContext(ctx, "outbox: giving up on message, marked failed",
+				"uuid", failed.uuid, "topic", failed.topic, "attempts", attempts, "error", pubErr)
+		}
+	}
+	if len(ids) > 0 {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE `+table+` SET published_at = now() WHERE id = ANY($1)`, ids,
+		); err != nil {
+			return 0, fmt.Errorf("outbox: mark published: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("outbox: commit relay tx: %w", err)
+	}
+	return len(ids), pubErr
+}
+
+// claimBatch locks up to limit pending rows, skipping rows locked by other
+// relays and rows marked failed.
+func claimBatch(ctx context.Context, tx *sql.Tx, limit int) ([]claimed, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, uuid, topic, payload, metadata, created_at
+		FROM `+table+`
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: claim batch: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var batch []claimed
+	for rows.Next() {
+		var (
+			row     claimed
+			rawMeta []byte
+		)
+		if err := rows.Scan(&row.id, &row.uuid, &row.topic, &row.payload, &rawMeta, &row.createdAt); err != nil {
+			return nil, fmt.Errorf("outbox: scan row: %w", err)
+		}
+		if err := json.Unmarshal(rawMeta, &row.metadata); err != nil {
+			return nil, fmt.Errorf("outbox: unmarshal metadata of %s: %w", row.uuid, err)
+		}
+		batch = append(batch, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("outbox: claim batch: %w", err)
+	}
+	return batch, nil
+}
+
+// Stats returns the current outbox backlog.
+func (r *Relay) Stats(ctx context.Context) (Stats, error) {
+	var (
+		s      Stats
+		oldest sql.NullTime
+	)
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			count(*) FILTER (WHERE failed_at IS NULL),
+			min(created_at) FILTER (WHERE failed_at IS NULL),
+			count(*) FILTER (WHERE failed_at IS NOT NULL)
+		FROM `+table+`
+		WHERE published_at IS NULL`,
+	).Scan(&s.Pending, &oldest, &s.Failed); err != nil {
+		return Stats{}, fmt.Errorf("outbox: stats: %w", err)
+	}
+	if oldest.Valid {
+		s.Lag = time.Since(oldest.Time)
+	}
+	return s, nil
+}
+
+// Cleanup deletes rows published more than retention ago and returns how many
+// were removed. Unpublished rows are never deleted.
+func (r *Relay) Cleanup(ctx context.Context, retention time.Duration) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM `+table+` WHERE published_at < $1`,
+		time.Now().Add(-retention),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: cleanup: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n, nil
+}
+
+type relayMetrics struct {
+	published metric.Int64Counter
+	failures  metric.Int64Counter
+	failed    metric.Int64Counter
+	delay     metric.Float64Histogram
+	gauges    metric.Registration // outbox.pending, outbox.lag and outbox.failed
+}
+
+func newRelayMetrics(meter metric.Meter, r *Relay) (*relayMetrics, error) {
+	var (
+		m   relayMetrics
+		err error
+	)
+	if m.published, err = meter.Int64Counter("outbox.relay.published",
+		metric.WithDescription("Outbox messages published to the event bus."),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		return nil, fmt.Errorf("outbox: published counter: %w", err)
+	}
+	if m.failures, err = meter.Int64Counter("outbox.relay.failures",
+		metric.WithDescription("Outbox messages that failed to publish."),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		return nil, fmt.Errorf("outbox: failures counter: %w", err)
+	}
+	if m.failed, err = meter.Int64Counter("outbox.relay.failed",
+		metric.WithDescription("Outbox messages marked failed after exhausting their publish attempts."),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		return nil, fmt.Errorf("outbox: failed counter: %w", err)
+	}
+	if m.delay, err = meter.Float64Histogram("outbox.relay.delay",
+		metric.WithDescription("Time from writing a message to the outbox to publishing it."),
+		metric.WithUnit("s"),
+	); err != nil {
+		return nil, fmt.Errorf("outbox: delay histogram: %w", err)
+	}
+
+	pending, err := meter.Int64ObservableGauge("outbox.pending",
+		metric.WithDescription("Unpublished outbox messages."),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: pending gauge: %w", err)
+	}
+	lag, err := meter.Float64ObservableGauge("outbox.lag",
+		metric.WithDescription("Age of the oldest unpublished outbox message."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: lag gauge: %w", err)
+	}
+	failed, err := meter.Int64ObservableGauge("outbox.failed",
+		metric.WithDescription("Outbox messages marked failed, awaiting an operator."),
+		metric.WithUnit("{message}"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: failed gauge: %w", err)
+	}
+	if m.gauges, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		s, err := r.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		o.ObserveInt64(pending, s.Pending)
+		o.ObserveFloat64(lag, s.Lag.Seconds())
+		o.ObserveInt64(failed, s.Failed)
+		return nil
+	}, pending, lag, failed); err != nil {
+		return nil, fmt.Errorf("outbox: register gauges: %w", err)
+	}
+	return &m, nil
+}
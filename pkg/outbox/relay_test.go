@@ -0,0 +1,328 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+
+	"github.com/ghuser/ghproject/pkg/config"
+	"github.com/ghuser/ghproject/pkg/logger"
+)
+
+// fakeOutbox is a database/sql driver that keeps the outbox table in memory
+// and understands the statements RelayBatch and Stats send. Writes apply immediately;
+// transactions only group them.
+type fakeOutbox struct {
+	mu   sync.Mutex
+	rows []*fakeRow
+}
+
+type fakeRow struct {
+	id        int64
+	uuid      string
+	topic     string
+	createdAt time.Time
+	published bool
+	failed    bool
+	attempts  int64
+	lastError string
+}
+
+func (f *fakeOutbox) add(uuids ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, id := range uuids {
+		f.rows = append(f.rows, &fakeRow{
+			id: int64(len(f.rows) + 1), uuid: id, topic: "test.topic", createdAt: time.Now(),
+		})
+	}
+}
+
+func (f *fakeOutbox) row(uuid string) fakeRow {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, r := range f.rows {
+		if r.uuid == uuid {
+			return *r
+		}
+	}
+	return fakeRow{}
+}
+
+func (f *fakeOutbox) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeOutbox) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ f *fakeOutbox }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error)                          { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                                                 { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                                    { return c, nil }
+func (c fakeConn) Commit() error                                                { return nil }
+func (c fakeConn) Rollback() error                                              { return nil }
+func (c fakeConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) { return c, nil }
+
+// CheckNamedValue passes the []int64 of mark-published through, as pgx does,
+// and converts other arguments as database/sql does by default.
+func (c fakeConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if _, ok := nv.Value.([]int64); ok {
+		return nil
+	}
+	v, err := driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	nv.Value = v
+	return err
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.Contains(query, "SET published_at") {
+		return nil, fmt.Errorf("unexpected exec: %s", query)
+	}
+	ids := args[0].Value.([]int64)
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	for _, r := range c.f.rows {
+		if slices.Contains(ids, r.id) {
+			r.published = true
+		}
+	}
+	return driver.RowsAffected(len(ids)), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	switch {
+	case strings.Contains(query, "FOR UPDATE SKIP LOCKED"):
+		rows := &fakeRows{columns: []string{"id", "uuid", "topic", "payload", "metadata", "created_at"}}
+		for _, r := range c.f.rows {
+			if !r.published && !r.failed && len(rows.values) < int(args[0].Value.(int64)) {
+				rows.values = append(rows.values, []driver.Value{r.id, r.uuid, r.topic, []byte("{}"), []byte(`{"k":"v"}`), r.createdAt})
+			}
+		}
+		return rows, nil
+	case strings.Contains(query, "SET attempts"):
+		id, lastError, maxAttempts := args[0].Value.(int64), args[1].Value.(string), args[2].Value.(int64)
+		for _, r := range c.f.rows {
+			if r.id == id {
+				r.attempts++
+				r.lastError = lastError
+				r.failed = r.attempts >= maxAttempts
+				return &fakeRows{columns: []string{"attempts", "failed"}, values: [][]driver.Value{{r.attempts, r.failed}}}, nil
+			}
+		}
+		return &fakeRows{}, nil
+	case strings.Contains(query, "count(*) FILTER"):
+		var pending, failed int64
+		var oldest any
+		for _, r := range c.f.rows {
+			switch {
+			case r.published:
+			case r.failed:
+				failed++
+			default:
+				pending++
+				if oldest == nil {
+					oldest = r.createdAt
+				}
+			}
+		}
+		return &fakeRows{columns: []string{"pending", "oldest", "failed"}, values: [][]driver.Value{{pending, oldest, failed}}}, nil
+	default:
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// fakePublisher records published message UUIDs and fails those in failing.
+type fakePublisher struct {
+	published []string
+	failing   map[string]bool
+}
+
+func (p *fakePublisher) Publish(_ context.Context, _ string, msgs ...*message.Message) error {
+	for _, msg := range msgs {
+		p.published = append(p.published, msg.UUID)
+		if p.failing[msg.UUID] {
+			return errors.New("broker rejected message")
+		}
+		if msg.Metadata.Get("k") != "v" {
+			return fmt.Errorf("metadata of %s not relayed", msg.UUID)
+		}
+	}
+	return nil
+}
+
+func newTestRelay(t *testing.T, pub EventPublisher, cfg RelayConfig) (*Relay, *fakeOutbox) {
+	t.Helper()
+	f := &fakeOutbox{}
+	db := sql.OpenDB(f)
+	t.Cleanup(func() { _ = db.Close() })
+	r, err := NewRelay(db, pub, logger.New(&config.Config{LogLevel: "error"}), cfg)
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+	return r, f
+}
+
+// TestRelayBatch_PublishesInOrder verifies batches claim pending rows in
+// insertion order, publish them and mark them published.
+func TestRelayBatch_PublishesInOrder(t *testing.T) {
+	pub := &fakePublisher{}
+	r, f := newTestRelay(t, pub, RelayConfig{BatchSize: 2})
+	f.add("a", "b", "c")
+	ctx := context.Background()
+
+	for i, want := range []int{2, 1, 0} {
+		if n, err := r.RelayBatch(ctx); n != want || err != nil {
+			t.Fatalf("batch %d: got %d, %v; want %d published", i+1, n, err, want)
+		}
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(pub.published, want) {
+		t.Errorf("published: got %v, want %v", pub.published, want)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if !f.row(id).published {
+			t.Errorf("row %s not marked published", id)
+		}
+	}
+}
+
+// TestRelayBatch_Failure verifies a failing row stops its batch, is retried
+// ahead of later rows until MaxAttempts, then is marked failed and skipped.
+func TestRelayBatch_Failure(t *testing.T) {
+	pub := &fakePublisher{failing: map[string]bool{"b": true}}
+	r, f := newTestRelay(t, pub, RelayConfig{MaxAttempts: 3})
+	f.add("a", "b", "c")
+	ctx := context.Background()
+
+	for i, want := range []int{1, 0, 0} {
+		if n, err := r.RelayBatch(ctx); n != want || err == nil {
+			t.Fatalf("batch %d: got %d, %v; want %d published and an error", i+1, n, err, want)
+		}
+		if f.row("c").published {
+			t.Fatalf("batch %d: row c published ahead of failing row b", i+1)
+		}
+	}
+	if n, err := r.RelayBatch(ctx); n != 1 || err != nil {
+		t.Fatalf("batch after giving up on b: got %d, %v; want c published", n, err)
+	}
+
+	if want := []string{"a", "b", "b", "b", "c"}; !slices.Equal(pub.published, want) {
+		t.Errorf("published: got %v, want %v", pub.published, want)
+	}
+	b := f.row("b")
+	if b.published || !b.failed || b.attempts != 3 || !strings.Contains(b.lastError, "broker rejected message") {
+		t.Errorf("row b: got %+v, want failed after 3 attempts with the last error", b)
+	}
+	if !f.row("a").published || !f.row("c").published {
+		t.Error("rows around the failed one not marked published")
+	}
+}
+
+// TestNextBackoff verifies the failure backoff doubles up to the cap.
+func TestNextBackoff(t *testing.T) {
+	delay := time.Second
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		delay = nextBackoff(delay, 10*time.Second)
+		if delay != w {
+			t.Errorf("step %d: got %v, want %v", i+1, delay, w)
+		}
+	}
+}
+
+// TestNewRelay_Defaults verifies zero config values fall back to the defaults.
+func TestNewRelay_Defaults(t *testing.T) {
+	r, err := NewRelay(nil, nil, nil, RelayConfig{})
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+	if r.cfg.BatchSize != DefaultBatchSize || r.cfg.PollInterval != DefaultPollInterval ||
+		r.cfg.MaxBackoff != DefaultMaxBackoff || r.cfg.MaxAttempts != DefaultMaxAttempts || r.cfg.Retention != 0 {
+		t.Errorf("unexpected defaults: %+v", r.cfg)
+	}
+
+	r, err = NewRelay(nil, nil, nil, RelayConfig{PollInterval: time.Minute})
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+	if r.cfg.MaxBackoff != time.Minute {
+		t.Errorf("max backoff below poll interval: got %v, want %v", r.cfg.MaxBackoff, time.Minute)
+	}
+}
+
+// TestTxPublisher_Closed verifies a closed publisher rejects further writes.
+func TestTxPublisher_Closed(t *testing.T) {
+	pub := NewTxPublisher(context.Background(), nil)
+	if err := pub.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := pub.Publish("topic", message.NewMessage("id", nil)); !errors.Is(err, ErrPublisherClosed) {
+		t.Errorf("expected ErrPublisherClosed, got %v", err)
+	}
+}
+
+// TestRun_UnregistersGauges verifies the outbox gauges are observed until Run
+// returns and not after.
+func TestRun_UnregistersGauges(t *testing.T) {
+	r, f := newTestRelay(t, &fakePublisher{}, RelayConfig{})
+	reader := sdkmetric.NewManualReader()
+	m, err := newRelayMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"), r)
+	if err != nil {
+		t.Fatalf("newRelayMetrics: %v", err)
+	}
+	r.metrics = m
+	f.add("a")
+
+	pending := func() (int64, bool) {
+		t.Helper()
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(context.Background(), &rm); err != nil {
+			t.Fatalf("collect: %v", err)
+		}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				if g, ok := m.Data.(metricdata.Gauge[int64]); ok && m.Name == "outbox.pending" && len(g.DataPoints) > 0 {
+					return g.DataPoints[0].Value, true
+				}
+			}
+		}
+		return 0, false
+	}
+	if n, ok := pending(); !ok || n != 1 {
+		t.Fatalf("before Run: outbox.pending = %d, %v; want 1", n, ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r.Run(ctx)
+	if n, ok := pending(); ok {
+		t.Errorf("after Run: outbox.pending = %d, want no observation", n)
+	}
+}
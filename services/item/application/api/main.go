@@ -14,6 +14,10 @@ func ItemRoutes(r chi.Router, a *app.Application) {
 	r.Group(func(r chi.Router) {
 		r.Route("/item", func(r chi.Router) {
 			r.Post("/", handlers.NewPostItemHandler(svcs).Execute)
+			r.Get("/", handlers.NewListItemsHandler(svcs, a.Cursors).Execute)
+			r.Get("/{id}", handlers.NewGetItemHandler(svcs).Execute)
+			r.Patch("/{id}", handlers.NewPatchItemHandler(svcs).Execute)
+			r.Delete("/{id}", handlers.NewDeleteItemHandler(svcs).Execute)
 		})
 	})
 }
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ghuser/ghproject/pkg/auth"
+	"github.com/ghuser/ghproject/pkg/errhttp"
+	"github.com/ghuser/ghproject/pkg/httpx"
+	appsvcs "github.com/ghuser/ghproject/services/item/application/services"
+)
+
+// DeleteItemHandler handles DELETE /item/{id} requests.
+type DeleteItemHandler struct {
+	svc *appsvcs.Services
+}
+
+// NewDeleteItemHandler returns a DeleteItemHandler backed by the given services.
+func NewDeleteItemHandler(svc *appsvcs.Services) *DeleteItemHandler {
+	return &DeleteItemHandler{svc: svc}
+}
+
+// Execute deletes an item.
+//
+//	@Summary		Delete item
+//	@Description	Deletes an item scoped to the caller's organization
+//	@Tags			items
+//	@Produce		json
+//	@Param			id	path	string	true	"Item ID"	format(uuid)
+//	@Success		204
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Router			/item/{id} [delete]
+func (h *DeleteItemHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	orgID, err := auth.OrgIDFromCtx(r.Context())
+	if err != nil {
+		httpx.JSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	id, ok := itemIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Item.Delete(r.Context(), orgID, id); err != nil {
+		errhttp.WriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/ghuser/ghproject/pkg/auth"
+	"github.com/ghuser/ghproject/pkg/errhttp"
+	"github.com/ghuser/ghproject/pkg/httpx"
+	appsvcs "github.com/ghuser/ghproject/services/item/application/services"
+	"github.com/ghuser/ghproject/services/item/domain/models"
+)
+
+// ItemResponse is the public representation of a single item.
+type ItemResponse struct {
+	ID        uuid.UUID `json:"id"         example:"123e4567-e89b-12d3-a456-426614174000"`
+	OrgID     uuid.UUID `json:"org_id"     example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name      string    `json:"name"       example:"Sample Item"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-15T10:30:00Z"`
+} // @name ItemResponse
+
+// newItemResponse maps a domain Item to its API representation.
+func newItemResponse(item *models.Item) ItemResponse {
+	return ItemResponse{
+		ID:        item.ID,
+		OrgID:     item.OrgID,
+		Name:      item.Name.String(),
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+// GetItemHandler handles GET /item/{id} requests.
+type GetItemHandler struct {
+	svc *appsvcs.Services
+}
+
+// NewGetItemHandler returns a GetItemHandler backed by the given services.
+func NewGetItemHandler(svc *appsvcs.Services) *GetItemHandler {
+	return &GetItemHandler{svc: svc}
+}
+
+// Execute returns a single item owned by the caller's organization.
+//
+//	@Summary		Get item
+//	@Description	Returns a single item scoped to the caller's organization
+//	@Tags			items
+//	@Produce		json
+//	@Param			id	path		string	true	"Item ID"	format(uuid)
+//	@Success		200	{object}	ItemResponse
+//	@Failure		400	{object}	ErrorResponse
+//	@Failure		401	{object}	ErrorResponse
+//	@Failure		404	{object}	ErrorResponse
+//	@Router			/item/{id} [get]
+func (h *GetItemHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	orgID, err := auth.OrgIDFromCtx(r.Context())
+	if err != nil {
+		httpx.JSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	id, ok := itemIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.Item.GetByID(r.Context(), orgID, id)
+	if err != nil {
+		errhttp.WriteError(w, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, newItemResponse(item))
+}
+
+// itemIDFromPath parses the {id} URL parameter as a UUID and writes a 400
+// response if it is malformed. Returns (id, true) on success.
+func itemIDFromPath(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		httpx.JSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid item id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
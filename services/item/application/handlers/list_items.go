@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ghuser/ghproject/pkg/auth"
+	"github.com/ghuser/ghproject/pkg/errhttp"
+	"github.com/ghuser/ghproject/pkg/httpx"
+	appsvcs "github.com/ghuser/ghproject/services/item/application/services"
+	"github.com/ghuser/ghproject/services/item/domain/repositories"
+)
+
+// ListItemsResponse is a page of items. Total is present only when include_total=true;
+// NextCursor is omitted on the last page.
+type ListItemsResponse struct {
+	Items      []ItemResponse `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty" example:"eyJ0IjoiMjAyNC0wMS0xNVQxMDozMDowMFoiLCJpZCI6IjEyMyJ9.c2ln"`
+	Total      *int           `json:"total,omitempty"       example:"42"`
+	Limit      int            `json:"limit"                 example:"20"`
+} // @name ListItemsResponse
+
+// ListItemsHandler handles GET /item requests.
+type ListItemsHandler struct {
+	svc     *appsvcs.Services
+	cursors *httpx.CursorCodec
+}
+
+// NewListItemsHandler returns a ListItemsHandler backed by the given services.
+// cursors signs and verifies the opaque next_cursor tokens.
+func NewListItemsHandler(svc *appsvcs.Services, cursors *httpx.CursorCodec) *ListItemsHandler {
+	return &ListItemsHandler{svc: svc, cursors: cursors}
+}
+
+// Execute returns a page of items owned by the caller's organization.
+//
+//	@Summary		List items
+//	@Description	Returns a page of items scoped to the caller's organization, newest first.
+//	@Description	Pass next_cursor from the previous response as cursor to fetch the following page.
+//	@Tags			items
+//	@Produce		json
+//	@Param			limit			query		int		false	"Page size (1-100)"	default(20)	minimum(1)	maximum(100)
+//	@Param			cursor			query		string	false	"Opaque cursor from a previous next_cursor"
+//	@Param			offset			query		int		false	"Number of items to skip; cannot be combined with cursor"	default(0)	minimum(0)
+//	@Param			include_total	query		bool	false	"Include the total item count"	default(false)
+//	@Success		200				{object}	ListItemsResponse
+//	@Failure		400				{object}	ErrorResponse
+//	@Failure		401				{object}	ErrorResponse
+//	@Router			/item [get]
+func (h *ListItemsHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	orgID, err := auth.OrgIDFromCtx(r.Context())
+	if err != nil {
+		httpx.JSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	params, err := httpx.ParsePageParams(r, h.cursors)
+	if err != nil {
+		httpx.JSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	opts := repositories.QueryOpts{
+		Limit:     params.Limit,
+		Offset:    params.Offset,
+		WithTotal: params.IncludeTotal,
+	}
+	if params.Cursor != nil {
+		opts.After = &repositories.Cursor{CreatedAt: params.Cursor.CreatedAt, ID: params.Cursor.ID}
+	}
+
+	page, err := h.svc.Item.List(r.Context(), orgID, opts)
+	if err != nil {
+		errhttp.WriteError(w, err)
+		return
+	}
+
+	resp := ListItemsResponse{
+		Items: make([]ItemResponse, len(page.Items)),
+		Total: page.Total,
+		Limit: params.Limit,
+	}
+	for i, item := range page.Items {
+		resp.Items[i] = newItemResponse(item)
+	}
+	if page.NextCursor != nil {
+		resp.NextCursor = h.cursors.Encode(httpx.Cursor{
+			CreatedAt: page.NextCursor.CreatedAt,
+			ID:        page.NextCursor.ID,
+		})
+	}
+	httpx.JSON(w, http.StatusOK, resp)
+}
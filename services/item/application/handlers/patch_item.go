@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ghuser/ghproject/pkg/auth"
+	"github.com/ghuser/ghproject/pkg/errhttp"
+	"github.com/ghuser/ghproject/pkg/httpx"
+	pkgvalidator "github.com/ghuser/ghproject/pkg/validator"
+	appsvcs "github.com/ghuser/ghproject/services/item/application/services"
+)
+
+// UpdateItemRequest is the request body for PATCH /item/{id}.
+type UpdateItemRequest struct {
+	Name string `json:"name" validate:"required,min=3,max=255" example:"Renamed Item"`
+} // @name UpdateItemRequest
+
+// PatchItemHandler handles PATCH /item/{id} requests.
+type PatchItemHandler struct {
+	svc *appsvcs.Services
+}
+
+// NewPatchItemHandler returns a PatchItemHandler backed by the given services.
+func NewPatchItemHandler(svc *appsvcs.Services) *PatchItemHandler {
+	return &PatchItemHandler{svc: svc}
+}
+
+// Execute renames an existing item.
+//
+//	@Summary		Update item
+//	@Description	Renames an item scoped to the caller's organization
+//	@Tags			items
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string				true	"Item ID"	format(uuid)
+//	@Param			request	body		UpdateItemRequest	true	"Item update request"
+//	@Success		200		{object}	ItemResponse
+//	@Failure		400		{object}	ErrorResponse
+//	@Failure		401		{object}	ErrorResponse
+//	@Failure		404		{object}	ErrorResponse
+//	@Failure		422		{object}	ErrorResponse
+//	@Router			/item/{id} [patch]
+func (h *PatchItemHandler) Execute(w http.ResponseWriter, r *http.Request) {
+	orgID, err := auth.OrgIDFromCtx(r.Context())
+	if err != nil {
+		httpx.JSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
+
+	id, ok := itemIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := pkgvalidator.ValidateRequest[UpdateItemRequest](w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.svc.Item.Update(r.Context(), orgID, id, req.Name)
+	if err != nil {
+		errhttp.WriteError(w, err)
+		return
+	}
+
+	httpx.JSON(w, http.StatusOK, newItemResponse(item))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/ghuser/ghproject/pkg/auth"
 	"github.com/ghuser/ghproject/pkg/errhttp"
 	"github.com/ghuser/ghproject/pkg/httpx"
 	pkgvalidator "github.com/ghuser/ghproject/pkg/validator"
@@ -55,18 +56,18 @@ func NewPostItemHandler(svc *appsvcs.Services) *PostItemHandler {
 //	@Failure		422		{object}	ErrorResponse
 //	@Router			/item [post]
 func (h *PostItemHandler) Execute(w http.ResponseWriter, r *http.Request) {
-	//orgID, err := auth.OrgIDFromCtx(r.Context())
-	//if err != nil {
-	//	httpx.JSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
-	//	return
-	//}
+	orgID, err := auth.OrgIDFromCtx(r.Context())
+	if err != nil {
+		httpx.JSON(w, http.StatusUnauthorized, ErrorResponse{Error: "authentication required"})
+		return
+	}
 
 	req, ok := pkgvalidator.ValidateRequest[CreateItemRequest](w, r)
 	if !ok {
 		return
 	}
 
-	item, err := h.svc.Item.Create(r.Context(), uuid.New(), req.Name)
+	item, err := h.svc.Item.Create(r.Context(), orgID, req.Name)
 	if err != nil {
 		errhttp.WriteError(w, err)
 		return
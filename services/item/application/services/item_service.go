@@ -6,26 +6,40 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
 
 	pkgcache "github.com/ghuser/ghproject/pkg/cache"
+	"github.com/ghuser/ghproject/pkg/database"
+	"github.com/ghuser/ghproject/pkg/logger"
 	itemdomain "github.com/ghuser/ghproject/services/item/domain"
 	"github.com/ghuser/ghproject/services/item/domain/models"
 	"github.com/ghuser/ghproject/services/item/domain/repositories"
 	domainsvcs "github.com/ghuser/ghproject/services/item/domain/services"
 )
 
-// ItemService orchestrates creation and retrieval of Items.
-// Event publishing is handled by the repository layer (outbox pattern).
-// Reads are served from Redis cache when available.
+// ItemService orchestrates creation, retrieval, update and deletion of Items.
+// Event publishing (item.created, item.updated, item.deleted) is handled by the
+// repository layer (outbox pattern).
+// Each write runs in a unit of work, so its reads, writes and events commit together.
+// Single-item reads and list pages are served from Redis cache when available.
 type ItemService struct {
 	repo  repositories.ItemRepository
+	uow   *database.UnitOfWork
 	cache *pkgcache.ItemCache
+	lists *pkgcache.ItemListCache
+	log   logger.Logger
 }
 
-// NewItemService returns an ItemService wired with the given repository and cache.
-func NewItemService(repo repositories.ItemRepository, itemCache *pkgcache.ItemCache) *ItemService {
-	return &ItemService{repo: repo, cache: itemCache}
+// NewItemService returns an ItemService wired with the given repository, unit of
+// work and caches. A nil uow leaves each repository call in its own transaction;
+// a nil cache disables it. Cache failures the service recovers from are logged to log.
+func NewItemService(
+	repo repositories.ItemRepository,
+	uow *database.UnitOfWork,
+	itemCache *pkgcache.ItemCache,
+	listCache *pkgcache.ItemListCache,
+	log logger.Logger,
+) *ItemService {
+	return &ItemService{repo: repo, uow: uow, cache: itemCache, lists: listCache, log: log}
 }
 
 // Create validates and persists an Item. The repository publishes ItemCreatedEvent.
@@ -44,75 +58,188 @@ func (s *ItemService) Create(ctx context.Context, orgID uuid.UUID, name string)
 		return nil, fmt.Errorf("%w: %w", itemdomain.ErrInvalidItemName, err)
 	}
 
-	if err := s.repo.Save(ctx, item); err != nil {
+	if err := s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		return s.repo.Save(ctx, item)
+	}); err != nil {
 		return nil, fmt.Errorf("save item: %w", err)
 	}
 
 	return item, nil
 }
 
-// GetByID retrieves an Item using a read-through cache pattern:
-//  1. Check Redis cache first.
-//  2. On cache miss (or cache error), query Postgres.
-//  3. Asynchronously warm the cache with the Postgres result.
+// GetByID retrieves an Item through the read-through cache: a miss loads it
+// from the primary once for all concurrent callers and caches the result, or
+// the item's absence. Redis errors fall back to Postgres.
 func (s *ItemService) GetByID(ctx context.Context, orgID, id uuid.UUID) (*models.Item, error) {
-	if s.cache != nil {
-		if cached, err := s.cache.Get(ctx, orgID, id); err == nil {
-			return &models.Item{
-				ID:        cached.ID,
-				OrgID:     cached.OrgID,
-				Name:      models.ItemName(cached.Name),
-				CreatedAt: cached.CreatedAt,
-			}, nil
-		} else if !errors.Is(err, redis.Nil) {
-			// Cache error — log in production; fall through to Postgres.
-			_ = err
+	if s.cache == nil {
+		item, err := s.repo.GetByID(ctx, orgID, id)
+		if err != nil {
+			return nil, fmt.Errorf("get item: %w", err)
 		}
+		return item, nil
 	}
 
-	item, err := s.repo.GetByID(ctx, orgID, id)
+	cached, err := s.cache.GetOrLoad(ctx, orgID, id, func(ctx context.Context) (*pkgcache.CachedItem, error) {
+		// A replica may not have replayed the write that evicted the item yet.
+		item, err := s.repo.GetByID(database.WithPrimary(ctx), orgID, id)
+		if errors.Is(err, itemdomain.ErrItemNotFound) {
+			return nil, pkgcache.ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &pkgcache.CachedItem{
+			ID:        item.ID,
+			OrgID:     item.OrgID,
+			Name:      item.Name.String(),
+			CreatedAt: item.CreatedAt,
+		}, nil
+	})
+	if errors.Is(err, pkgcache.ErrNotFound) {
+		return nil, fmt.Errorf("get item: %w", itemdomain.ErrItemNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("get item: %w", err)
 	}
+	return &models.Item{
+		ID:        cached.ID,
+		OrgID:     cached.OrgID,
+		Name:      models.ItemName(cached.Name),
+		CreatedAt: cached.CreatedAt,
+	}, nil
+}
 
-	if s.cache != nil {
-		go func() {
-			_ = s.cache.Set(context.Background(), &pkgcache.CachedItem{
-				ID:        item.ID,
-				OrgID:     item.OrgID,
-				Name:      item.Name.String(),
-				CreatedAt: item.CreatedAt,
-			})
-		}()
+// Update renames an existing item scoped to the given org and evicts it from the cache.
+// Returns ErrItemNotFound if no matching item exists and ErrInvalidItemName if the
+// new name violates domain constraints.
+func (s *ItemService) Update(ctx context.Context, orgID, id uuid.UUID, name string) (*models.Item, error) {
+	itemName, err := models.NewItemName(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", itemdomain.ErrInvalidItemName, err)
+	}
+	if err := domainsvcs.ValidateName(itemName); err != nil {
+		return nil, fmt.Errorf("%w: %w", itemdomain.ErrInvalidItemName, err)
 	}
 
+	var item *models.Item
+	if err := s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		var err error
+		if item, err = s.repo.GetByID(ctx, orgID, id); err != nil {
+			return fmt.Errorf("get item: %w", err)
+		}
+		item.Name = itemName
+		if err := s.repo.Update(ctx, item); err != nil {
+			return fmt.Errorf("update item: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	s.evict(ctx, orgID, id)
 	return item, nil
 }
 
-// List returns a paginated slice of items for the org plus total count.
-func (s *ItemService) List(ctx context.Context, orgID uuid.UUID, opts repositories.QueryOpts) ([]*models.Item, int, error) {
-	items, total, err := s.repo.FindByOrgID(ctx, orgID, opts)
+// List returns a page of items for the org. See repositories.QueryOpts for
+// offset vs keyset pagination and the optional total count.
+//
+// Pages are cached per org and query shape, loaded from the primary and
+// flushed by the worker when the org's items change, so a page may lag a
+// write by the event delivery delay. Redis errors fall back to Postgres.
+func (s *ItemService) List(ctx context.Context, orgID uuid.UUID, opts repositories.QueryOpts) (*repositories.ItemPage, error) {
+	if s.lists == nil {
+		page, err := s.repo.FindByOrgID(ctx, orgID, opts)
+		if err != nil {
+			return nil, fmt.Errorf("list items: %w", err)
+		}
+		return page, nil
+	}
+
+	key := pkgcache.ItemListKey{OrgID: orgID, Limit: opts.Limit, Offset: opts.Offset, WithTotal: opts.WithTotal}
+	if opts.After != nil {
+		key.AfterCreatedAt, key.AfterID = opts.After.CreatedAt, opts.After.ID
+	}
+	cached, err := s.lists.GetOrLoad(ctx, key, func(ctx context.Context) (*pkgcache.CachedItemPage, error) {
+		page, err := s.repo.FindByOrgID(database.WithPrimary(ctx), orgID, opts)
+		if err != nil {
+			return nil, err
+		}
+		return toCachedPage(page), nil
+	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("list items: %w", err)
+		return nil, fmt.Errorf("list items: %w", err)
+	}
+	return fromCachedPage(cached), nil
+}
+
+// toCachedPage converts a page of items to its cached read model.
+func toCachedPage(page *repositories.ItemPage) *pkgcache.CachedItemPage {
+	cached := &pkgcache.CachedItemPage{
+		Items: make([]pkgcache.CachedItem, len(page.Items)),
+		Total: page.Total,
+	}
+	for i, item := range page.Items {
+		cached.Items[i] = pkgcache.CachedItem{
+			ID:        item.ID,
+			OrgID:     item.OrgID,
+			Name:      item.Name.String(),
+			CreatedAt: item.CreatedAt,
+		}
+	}
+	if page.NextCursor != nil {
+		cached.NextCursor = &pkgcache.CachedCursor{CreatedAt: page.NextCursor.CreatedAt, ID: page.NextCursor.ID}
+	}
+	return cached
+}
+
+// fromCachedPage converts a cached page back to items.
+func fromCachedPage(cached *pkgcache.CachedItemPage) *repositories.ItemPage {
+	page := &repositories.ItemPage{
+		Items: make([]*models.Item, len(cached.Items)),
+		Total: cached.Total,
+	}
+	for i, item := range cached.Items {
+		page.Items[i] = &models.Item{
+			ID:        item.ID,
+			OrgID:     item.OrgID,
+			Name:      models.ItemName(item.Name),
+			CreatedAt: item.CreatedAt,
+		}
+	}
+	if cached.NextCursor != nil {
+		page.NextCursor = &repositories.Cursor{CreatedAt: cached.NextCursor.CreatedAt, ID: cached.NextCursor.ID}
 	}
-	return items, total, nil
+	return page
 }
 
 // Delete removes an item by ID scoped to the given org.
 // Returns ErrItemNotFound if no matching item exists.
 func (s *ItemService) Delete(ctx context.Context, orgID, id uuid.UUID) error {
-	exists, err := s.repo.Exists(ctx, orgID, id)
-	if err != nil {
-		return fmt.Errorf("check item: %w", err)
+	if err := s.inUnitOfWork(ctx, func(ctx context.Context) error {
+		return s.repo.Delete(ctx, orgID, id)
+	}); err != nil {
+		return fmt.Errorf("delete item: %w", err)
 	}
-	if !exists {
-		return itemdomain.ErrItemNotFound
+	s.evict(ctx, orgID, id)
+	return nil
+}
+
+// evict drops a changed item from the cache. The write has committed, so a
+// failure is logged rather than returned; the worker evicts the item again
+// when its event arrives.
+func (s *ItemService) evict(ctx context.Context, orgID, id uuid.UUID) {
+	if s.cache == nil {
+		return
 	}
-	if err := s.repo.Delete(ctx, orgID, id); err != nil {
-		return fmt.Errorf("delete item: %w", err)
+	if err := s.cache.Delete(context.WithoutCancel(ctx), orgID, id); err != nil {
+		s.log.WarnContext(ctx, "item cache eviction failed",
+			"org_id", orgID, "item_id", id, "error", err)
 	}
-	if s.cache != nil {
-		_ = s.cache.Delete(context.Background(), orgID, id)
+}
+
+// inUnitOfWork runs fn in the service's unit of work, or directly without one.
+func (s *ItemService) inUnitOfWork(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.uow == nil {
+		return fn(ctx)
 	}
-	return nil
+	return s.uow.Run(ctx, fn)
 }
@@ -3,6 +3,7 @@ package services
 import (
 	"github.com/ghuser/ghproject/pkg/app"
 	"github.com/ghuser/ghproject/pkg/cache"
+	"github.com/ghuser/ghproject/pkg/database"
 	"github.com/ghuser/ghproject/services/item/infrastructure/persistence/postgres"
 )
 
@@ -15,8 +16,10 @@ type Services struct {
 // New wires all item application services with infrastructure from the Application container.
 func New(a *app.Application) *Services {
 	repo := postgres.NewItemRepository(a.Db, a.EventBus)
+	uow := database.NewUnitOfWork(a.Db, a.EventBus)
 	itemCache := cache.NewItemCache(a.Redis)
+	listCache := cache.NewItemListCache(a.Redis)
 	return &Services{
-		Item: NewItemService(repo, itemCache),
+		Item: NewItemService(repo, uow, itemCache, listCache, a.Logger),
 	}
 }
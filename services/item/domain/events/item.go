@@ -6,11 +6,19 @@ import (
 	"github.com/google/uuid"
 )
 
-// TopicItemCreated is the Watermill topic published when an Item is created.
-const TopicItemCreated = "item.created"
+// Topics published by the item bounded context.
+const (
+	// TopicItemCreated is the Watermill topic published when an Item is created.
+	TopicItemCreated = "item.created"
+	// TopicItemUpdated is the Watermill topic published when an Item is changed.
+	TopicItemUpdated = "item.updated"
+	// TopicItemDeleted is the Watermill topic published when an Item is deleted.
+	TopicItemDeleted = "item.deleted"
+)
 
 // ItemCreatedEvent is published after a new Item is persisted.
-// Consumers subscribe via EventBus.Subscribe(ctx, events.TopicItemCreated).
+// Consumers subscribe via events.SubscribeTyped[ItemCreatedEvent]; the topic and
+// schema version are registered in infrastructure/messaging.
 type ItemCreatedEvent struct {
 	EventID    uuid.UUID `json:"event_id"` // Unique publish-time identifier for deduplication
 	Version    int       `json:"version"`  // Schema version; increment on breaking changes
@@ -19,3 +27,52 @@ type ItemCreatedEvent struct {
 	Name       string    `json:"name"`
 	OccurredAt time.Time `json:"occurred_at"`
 }
+
+// GetEventID returns the event's deduplication identifier.
+func (e ItemCreatedEvent) GetEventID() uuid.UUID { return e.EventID }
+
+// GetAggregateID returns the Item's ID, which orders the Item's events.
+func (e ItemCreatedEvent) GetAggregateID() uuid.UUID { return e.ItemID }
+
+// ItemSnapshot is the full state of an Item at a point in time.
+// Change events carry snapshots so consumers can rebuild read models without
+// querying the item service.
+type ItemSnapshot struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ItemUpdatedEvent is published after an existing Item is changed.
+// Before and After hold the state on either side of the change.
+type ItemUpdatedEvent struct {
+	EventID    uuid.UUID    `json:"event_id"` // Unique publish-time identifier for deduplication
+	Version    int          `json:"version"`  // Schema version; increment on breaking changes
+	ItemID     uuid.UUID    `json:"item_id"`
+	OrgID      uuid.UUID    `json:"org_id"`
+	Before     ItemSnapshot `json:"before"`
+	After      ItemSnapshot `json:"after"`
+	OccurredAt time.Time    `json:"occurred_at"`
+}
+
+// GetEventID returns the event's deduplication identifier.
+func (e ItemUpdatedEvent) GetEventID() uuid.UUID { return e.EventID }
+
+// GetAggregateID returns the Item's ID, which orders the Item's events.
+func (e ItemUpdatedEvent) GetAggregateID() uuid.UUID { return e.ItemID }
+
+// ItemDeletedEvent is published after an Item is deleted.
+// Before holds the last state of the Item prior to deletion.
+type ItemDeletedEvent struct {
+	EventID    uuid.UUID    `json:"event_id"` // Unique publish-time identifier for deduplication
+	Version    int          `json:"version"`  // Schema version; increment on breaking changes
+	ItemID     uuid.UUID    `json:"item_id"`
+	OrgID      uuid.UUID    `json:"org_id"`
+	Before     ItemSnapshot `json:"before"`
+	OccurredAt time.Time    `json:"occurred_at"`
+}
+
+// GetEventID returns the event's deduplication identifier.
+func (e ItemDeletedEvent) GetEventID() uuid.UUID { return e.EventID }
+
+// GetAggregateID returns the Item's ID, which orders the Item's events.
+func (e ItemDeletedEvent) GetAggregateID() uuid.UUID { return e.ItemID }
@@ -88,3 +88,79 @@ func TestTopicItemCreated_Value(t *testing.T) {
 		t.Errorf("expected %q, got %q", "item.created", events.TopicItemCreated)
 	}
 }
+
+func TestItemUpdatedEvent_JSONRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	original := events.ItemUpdatedEvent{
+		EventID:    uuid.New(),
+		Version:    1,
+		ItemID:     uuid.New(),
+		OrgID:      uuid.New(),
+		Before:     events.ItemSnapshot{Name: "Old Name", CreatedAt: createdAt},
+		After:      events.ItemSnapshot{Name: "New Name", CreatedAt: createdAt},
+		OccurredAt: time.Date(2025, 1, 16, 8, 0, 0, 0, time.UTC),
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+
+	var decoded events.ItemUpdatedEvent
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	if decoded.EventID != original.EventID || decoded.ItemID != original.ItemID || decoded.OrgID != original.OrgID {
+		t.Errorf("identifiers mismatch: got %+v, want %+v", decoded, original)
+	}
+	if decoded.Before.Name != "Old Name" || decoded.After.Name != "New Name" {
+		t.Errorf("snapshots mismatch: before %q, after %q", decoded.Before.Name, decoded.After.Name)
+	}
+	if !decoded.After.CreatedAt.Equal(createdAt) {
+		t.Errorf("After.CreatedAt: got %v, want %v", decoded.After.CreatedAt, createdAt)
+	}
+}
+
+func TestItemChangeEvents_JSONFieldNames(t *testing.T) {
+	cases := map[string]struct {
+		evt    any
+		fields []string
+	}{
+		"updated": {
+			evt:    events.ItemUpdatedEvent{EventID: uuid.New(), Version: 1, ItemID: uuid.New(), OrgID: uuid.New()},
+			fields: []string{"event_id", "version", "item_id", "org_id", "before", "after", "occurred_at"},
+		},
+		"deleted": {
+			evt:    events.ItemDeletedEvent{EventID: uuid.New(), Version: 1, ItemID: uuid.New(), OrgID: uuid.New()},
+			fields: []string{"event_id", "version", "item_id", "org_id", "before", "occurred_at"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(tc.evt)
+			if err != nil {
+				t.Fatalf("json.Marshal failed: %v", err)
+			}
+			var raw map[string]interface{}
+			if err := json.Unmarshal(data, &raw); err != nil {
+				t.Fatalf("unmarshal to map failed: %v", err)
+			}
+			for _, field := range tc.fields {
+				if _, ok := raw[field]; !ok {
+					t.Errorf("expected JSON field %q not found in: %s", field, data)
+				}
+			}
+		})
+	}
+}
+
+func TestItemTopics_Values(t *testing.T) {
+	if events.TopicItemUpdated != "item.updated" {
+		t.Errorf("expected %q, got %q", "item.updated", events.TopicItemUpdated)
+	}
+	if events.TopicItemDeleted != "item.deleted" {
+		t.Errorf("expected %q, got %q", "item.deleted", events.TopicItemDeleted)
+	}
+}
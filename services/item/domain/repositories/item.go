@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -9,9 +10,32 @@ import (
 )
 
 // QueryOpts contains pagination parameters for list queries.
+//
+// Two modes are supported:
+//   - Offset: After is nil; Offset rows are skipped. Simple but slow on deep pages
+//     and unstable when rows are inserted between requests.
+//   - Keyset: After is set; rows strictly after the cursor (in created_at DESC, id DESC
+//     order) are returned and Offset is ignored. Preferred for large orgs.
 type QueryOpts struct {
-	Limit  int // Maximum number of records to return
-	Offset int // Number of records to skip
+	Limit     int     // Maximum number of records to return
+	Offset    int     // Number of records to skip (offset mode only)
+	After     *Cursor // Position of the last row of the previous page (keyset mode)
+	WithTotal bool    // Also count all matching rows; costs an extra query
+}
+
+// Cursor identifies a row position in created_at DESC, id DESC order.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ItemPage is a single page of a list query.
+type ItemPage struct {
+	Items []*models.Item
+	// Total is the count of all matching rows, populated only when QueryOpts.WithTotal is set.
+	Total *int
+	// NextCursor points at the last item of this page; nil when there are no more rows.
+	NextCursor *Cursor
 }
 
 // ItemRepository is the persistence interface for the Item aggregate.
@@ -20,14 +44,16 @@ type ItemRepository interface {
 	Save(ctx context.Context, item *models.Item) error
 	GetByID(ctx context.Context, orgID, id uuid.UUID) (*models.Item, error)
 
-	// FindByOrgID retrieves a paginated list of items for the given org.
-	// Returns the items slice and the total count (ignoring pagination).
-	FindByOrgID(ctx context.Context, orgID uuid.UUID, opts QueryOpts) ([]*models.Item, int, error)
+	// FindByOrgID retrieves a page of items for the given org, newest first.
+	// The total count (ignoring pagination) is included only when opts.WithTotal is set.
+	FindByOrgID(ctx context.Context, orgID uuid.UUID, opts QueryOpts) (*ItemPage, error)
 
 	// Update persists changes to an existing Item.
+	// Returns ErrItemNotFound if no matching item exists.
 	Update(ctx context.Context, item *models.Item) error
 
 	// Delete removes an item by ID scoped to the given org.
+	// Returns ErrItemNotFound if no matching item exists.
 	Delete(ctx context.Context, orgID, id uuid.UUID) error
 
 	// Exists reports whether an item with the given ID exists for the given org.
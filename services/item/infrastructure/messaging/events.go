@@ -0,0 +1,25 @@
+// Package messaging registers the item bounded context's domain events with the
+// typed event registry in pkg/events. Any binary that publishes or consumes item
+// events must import it (a blank import is enough) so the registrations run.
+package messaging
+
+import (
+	"github.com/ghuser/ghproject/pkg/events"
+	domainevents "github.com/ghuser/ghproject/services/item/domain/events"
+)
+
+// Current schema versions. Bump when a payload changes incompatibly and register
+// an upcaster from the previous version in init, so messages already queued at
+// the old version are still delivered. Cover the new step with
+// eventstest.AssertUpcastable.
+const (
+	ItemCreatedVersion = 1
+	ItemUpdatedVersion = 1
+	ItemDeletedVersion = 1
+)
+
+func init() {
+	events.Register[domainevents.ItemCreatedEvent](domainevents.TopicItemCreated, ItemCreatedVersion)
+	events.Register[domainevents.ItemUpdatedEvent](domainevents.TopicItemUpdated, ItemUpdatedVersion)
+	events.Register[domainevents.ItemDeletedEvent](domainevents.TopicItemDeleted, ItemDeletedVersion)
+}
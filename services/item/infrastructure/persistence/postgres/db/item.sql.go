@@ -43,7 +43,7 @@ const findItemsByOrgID = `-- name: FindItemsByOrgID :many
 SELECT id, org_id, name, created_at
 FROM item.items
 WHERE org_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $2 OFFSET $3
 `
 
@@ -81,6 +81,55 @@ func (q *Queries) FindItemsByOrgID(ctx context.Context, arg FindItemsByOrgIDPara
 	return items, nil
 }
 
+const findItemsByOrgIDAfter = `-- name: FindItemsByOrgIDAfter :many
+SELECT id, org_id, name, created_at
+FROM item.items
+WHERE org_id = $1
+  AND (created_at, id) < ($2::timestamp, $3::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type FindItemsByOrgIDAfterParams struct {
+	OrgID          uuid.UUID
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+	Limit          int32
+}
+
+func (q *Queries) FindItemsByOrgIDAfter(ctx context.Context, arg FindItemsByOrgIDAfterParams) ([]ItemItem, error) {
+	rows, err := q.db.QueryContext(ctx, findItemsByOrgIDAfter,
+		arg.OrgID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ItemItem
+	for rows.Next() {
+		var i ItemItem
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrgID,
+			&i.Name,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getItemByID = `-- name: GetItemByID :one
 SELECT id, org_id, name, created_at
 FROM item.items
@@ -104,6 +153,30 @@ func (q *Queries) GetItemByID(ctx context.Context, arg GetItemByIDParams) (ItemI
 	return i, err
 }
 
+const getItemByIDForUpdate = `-- name: GetItemByIDForUpdate :one
+SELECT id, org_id, name, created_at
+FROM item.items
+WHERE id = $1 AND org_id = $2
+FOR UPDATE
+`
+
+type GetItemByIDForUpdateParams struct {
+	ID    uuid.UUID
+	OrgID uuid.UUID
+}
+
+func (q *Queries) GetItemByIDForUpdate(ctx context.Context, arg GetItemByIDForUpdateParams) (ItemItem, error) {
+	row := q.db.QueryRowContext(ctx, getItemByIDForUpdate, arg.ID, arg.OrgID)
+	var i ItemItem
+	err := row.Scan(
+		&i.ID,
+		&i.OrgID,
+		&i.Name,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const insertItem = `-- name: InsertItem :exec
 INSERT INTO item.items (id, org_id, name, created_at)
 VALUES ($1, $2, $3, $4)
@@ -14,7 +14,9 @@ type Querier interface {
 	CountItemsByOrgID(ctx context.Context, orgID uuid.UUID) (int64, error)
 	DeleteItem(ctx context.Context, arg DeleteItemParams) error
 	FindItemsByOrgID(ctx context.Context, arg FindItemsByOrgIDParams) ([]ItemItem, error)
+	FindItemsByOrgIDAfter(ctx context.Context, arg FindItemsByOrgIDAfterParams) ([]ItemItem, error)
 	GetItemByID(ctx context.Context, arg GetItemByIDParams) (ItemItem, error)
+	GetItemByIDForUpdate(ctx context.Context, arg GetItemByIDForUpdateParams) (ItemItem, error)
 	InsertItem(ctx context.Context, arg InsertItemParams) error
 	ItemExists(ctx context.Context, arg ItemExistsParams) (bool, error)
 	UpdateItem(ctx context.Context, arg UpdateItemParams) error
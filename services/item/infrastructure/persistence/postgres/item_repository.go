@@ -3,12 +3,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
-	"github.com/ThreeDotsLabs/watermill"
-	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 
@@ -18,18 +16,22 @@ import (
 	domainevents "github.com/ghuser/ghproject/services/item/domain/events"
 	"github.com/ghuser/ghproject/services/item/domain/models"
 	"github.com/ghuser/ghproject/services/item/domain/repositories"
+	"github.com/ghuser/ghproject/services/item/infrastructure/messaging"
 	"github.com/ghuser/ghproject/services/item/infrastructure/persistence/postgres/db"
 )
 
 // ItemRepository implements repositories.ItemRepository against PostgreSQL.
 type ItemRepository struct {
 	db  *database.Database
-	bus *events.EventBus
+	bus events.Bus
 }
 
 // NewItemRepository returns an ItemRepository backed by the given connection pool
-// and event bus. The bus is used to publish ItemCreatedEvents after a successful save.
-func NewItemRepository(database *database.Database, bus *events.EventBus) *ItemRepository {
+// and event bus. The bus is used to publish item lifecycle events (created, updated,
+// deleted) through the transactional outbox alongside each write. Writes join a
+// transaction or database.UnitOfWork carried by ctx; reads go through
+// database.Reader, so they may be served by a replica.
+func NewItemRepository(database *database.Database, bus events.Bus) *ItemRepository {
 	return &ItemRepository{db: database, bus: bus}
 }
 
@@ -52,7 +54,7 @@ func (r *ItemRepository) Save(ctx context.Context, item *models.Item) error {
 		}
 
 		if r.bus != nil {
-			if err := r.publishCreated(tx, item); err != nil {
+			if err := r.publishCreated(ctx, tx, item); err != nil {
 				return fmt.Errorf("publish item created: %w", err)
 			}
 		}
@@ -62,7 +64,7 @@ func (r *ItemRepository) Save(ctx context.Context, item *models.Item) error {
 
 // GetByID retrieves an Item by ID scoped to the given org. Returns ErrItemNotFound if not found.
 func (r *ItemRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (*models.Item, error) {
-	q := db.New(r.db.DB())
+	q := db.New(r.db.Reader(ctx))
 	row, err := q.GetItemByID(ctx, db.GetItemByIDParams{
 		ID:    id,
 		OrgID: orgID,
@@ -76,59 +78,118 @@ func (r *ItemRepository) GetByID(ctx context.Context, orgID, id uuid.UUID) (*mod
 	return rowToItem(row), nil
 }
 
-// FindByOrgID retrieves a paginated list of items and total count for the given org.
-func (r *ItemRepository) FindByOrgID(ctx context.Context, orgID uuid.UUID, opts repositories.QueryOpts) ([]*models.Item, int, error) {
-	q := db.New(r.db.DB())
+// FindByOrgID retrieves a page of items for the given org, newest first.
+// Uses keyset pagination when opts.After is set and LIMIT/OFFSET otherwise.
+// One extra row is fetched to detect whether a next page exists.
+func (r *ItemRepository) FindByOrgID(ctx context.Context, orgID uuid.UUID, opts repositories.QueryOpts) (*repositories.ItemPage, error) {
+	q := db.New(r.db.Reader(ctx))
 
-	rows, err := q.FindItemsByOrgID(ctx, db.FindItemsByOrgIDParams{
-		OrgID:  orgID,
-		Limit:  int32(opts.Limit),
-		Offset: int32(opts.Offset),
-	})
+	var (
+		rows []db.ItemItem
+		err  error
+	)
+	if opts.After != nil {
+		rows, err = q.FindItemsByOrgIDAfter(ctx, db.FindItemsByOrgIDAfterParams{
+			OrgID:          orgID,
+			AfterCreatedAt: opts.After.CreatedAt,
+			AfterID:        opts.After.ID,
+			Limit:          int32(opts.Limit + 1),
+		})
+	} else {
+		rows, err = q.FindItemsByOrgID(ctx, db.FindItemsByOrgIDParams{
+			OrgID:  orgID,
+			Limit:  int32(opts.Limit + 1),
+			Offset: int32(opts.Offset),
+		})
+	}
 	if err != nil {
-		return nil, 0, fmt.Errorf("query items: %w", err)
+		return nil, fmt.Errorf("query items: %w", err)
 	}
 
-	total, err := q.CountItemsByOrgID(ctx, orgID)
-	if err != nil {
-		return nil, 0, fmt.Errorf("count items: %w", err)
+	page := &repositories.ItemPage{}
+	if len(rows) > opts.Limit {
+		rows = rows[:opts.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = &repositories.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
 	}
 
-	items := make([]*models.Item, len(rows))
+	if opts.WithTotal {
+		total, err := q.CountItemsByOrgID(ctx, orgID)
+		if err != nil {
+			return nil, fmt.Errorf("count items: %w", err)
+		}
+		n := int(total)
+		page.Total = &n
+	}
+
+	page.Items = make([]*models.Item, len(rows))
 	for i, row := range rows {
-		items[i] = rowToItem(row)
+		page.Items[i] = rowToItem(row)
 	}
-	return items, int(total), nil
+	return page, nil
 }
 
-// Update persists a name change to an existing Item.
+// Update persists a name change to an existing Item and publishes an
+// ItemUpdatedEvent within the same transaction. The row is locked while the
+// previous state is read so the event's Before snapshot is accurate under
+// concurrent writers. Returns ErrItemNotFound if no matching item exists.
 func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
-	q := db.New(r.db.DB())
-	if err := q.UpdateItem(ctx, db.UpdateItemParams{
-		ID:    item.ID,
-		OrgID: item.OrgID,
-		Name:  item.Name.String(),
-	}); err != nil {
-		return fmt.Errorf("update item: %w", err)
-	}
-	return nil
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		q := db.New(tx)
+		before, err := r.lockItem(ctx, q, item.OrgID, item.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := q.UpdateItem(ctx, db.UpdateItemParams{
+			ID:    item.ID,
+			OrgID: item.OrgID,
+			Name:  item.Name.String(),
+		}); err != nil {
+			return fmt.Errorf("update item: %w", err)
+		}
+
+		if r.bus != nil {
+			after := *before
+			after.Name = item.Name
+			if err := r.publishUpdated(ctx, tx, before, &after); err != nil {
+				return fmt.Errorf("publish item updated: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
-// Delete removes an item by ID scoped to the given org.
+// Delete removes an item by ID scoped to the given org and publishes an
+// ItemDeletedEvent within the same transaction.
+// Returns ErrItemNotFound if no matching item exists.
 func (r *ItemRepository) Delete(ctx context.Context, orgID, id uuid.UUID) error {
-	q := db.New(r.db.DB())
-	if err := q.DeleteItem(ctx, db.DeleteItemParams{
-		ID:    id,
-		OrgID: orgID,
-	}); err != nil {
-		return fmt.Errorf("delete item: %w", err)
-	}
-	return nil
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		q := db.New(tx)
+		before, err := r.lockItem(ctx, q, orgID, id)
+		if err != nil {
+			return err
+		}
+
+		if err := q.DeleteItem(ctx, db.DeleteItemParams{
+			ID:    id,
+			OrgID: orgID,
+		}); err != nil {
+			return fmt.Errorf("delete item: %w", err)
+		}
+
+		if r.bus != nil {
+			if err := r.publishDeleted(ctx, tx, before); err != nil {
+				return fmt.Errorf("publish item deleted: %w", err)
+			}
+		}
+		return nil
+	})
 }
 
 // Exists reports whether an item with the given ID exists for the given org.
 func (r *ItemRepository) Exists(ctx context.Context, orgID, id uuid.UUID) (bool, error) {
-	q := db.New(r.db.DB())
+	q := db.New(r.db.Reader(ctx))
 	exists, err := q.ItemExists(ctx, db.ItemExistsParams{
 		ID:    id,
 		OrgID: orgID,
@@ -139,27 +200,85 @@ func (r *ItemRepository) Exists(ctx context.Context, orgID, id uuid.UUID) (bool,
 	return exists, nil
 }
 
-func (r *ItemRepository) publishCreated(tx *sql.Tx, item *models.Item) error {
-	event := domainevents.ItemCreatedEvent{
+// lockItem reads an item with SELECT ... FOR UPDATE so it cannot change until
+// the surrounding transaction ends. Returns ErrItemNotFound if it does not exist.
+func (r *ItemRepository) lockItem(ctx context.Context, q *db.Queries, orgID, id uuid.UUID) (*models.Item, error) {
+	row, err := q.GetItemByIDForUpdate(ctx, db.GetItemByIDForUpdateParams{
+		ID:    id,
+		OrgID: orgID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, itemdomain.ErrItemNotFound
+		}
+		return nil, fmt.Errorf("lock item: %w", err)
+	}
+	return rowToItem(row), nil
+}
+
+func (r *ItemRepository) publishCreated(ctx context.Context, tx *sql.Tx, item *models.Item) error {
+	return publishEvent(ctx, r.bus, tx, domainevents.ItemCreatedEvent{
 		EventID:    uuid.New(),
-		Version:    1,
+		Version:    messaging.ItemCreatedVersion,
 		ItemID:     item.ID,
 		OrgID:      item.OrgID,
 		Name:       item.Name.String(),
 		OccurredAt: item.CreatedAt,
-	}
-	payload, err := json.Marshal(event)
+	})
+}
+
+func (r *ItemRepository) publishUpdated(ctx context.Context, tx *sql.Tx, before, after *models.Item) error {
+	return publishEvent(ctx, r.bus, tx, domainevents.ItemUpdatedEvent{
+		EventID:    uuid.New(),
+		Version:    messaging.ItemUpdatedVersion,
+		ItemID:     after.ID,
+		OrgID:      after.OrgID,
+		Before:     itemSnapshot(before),
+		After:      itemSnapshot(after),
+		OccurredAt: time.Now().UTC(),
+	})
+}
+
+func (r *ItemRepository) publishDeleted(ctx context.Context, tx *sql.Tx, before *models.Item) error {
+	return publishEvent(ctx, r.bus, tx, domainevents.ItemDeletedEvent{
+		EventID:    uuid.New(),
+		Version:    messaging.ItemDeletedVersion,
+		ItemID:     before.ID,
+		OrgID:      before.OrgID,
+		Before:     itemSnapshot(before),
+		OccurredAt: time.Now().UTC(),
+	})
+}
+
+// publishEvent writes event to its registered topic through a publisher bound
+// to tx, so the event is committed or rolled back together with the data change.
+// Inside a database.UnitOfWork the event is collected instead, and published
+// when the unit of work commits.
+func publishEvent[T any](ctx context.Context, bus events.Bus, tx *sql.Tx, event T) error {
+	topic, msg, err := events.NewTypedMessage(ctx, event)
 	if err != nil {
-		return fmt.Errorf("marshal event: %w", err)
+		return err
 	}
-	msg := message.NewMessage(watermill.NewUUID(), payload)
-	msg.Metadata.Set("event_id", event.EventID.String())
-	msg.Metadata.Set("event_version", "1")
-	p, err := r.bus.NewTxPublisher(tx)
+	if database.Collect(ctx, topic, msg) {
+		return nil
+	}
+
+	p, err := bus.NewTxPublisher(tx)
 	if err != nil {
 		return fmt.Errorf("create publisher: %w", err)
 	}
-	return p.Publish(domainevents.TopicItemCreated, msg)
+	if err := p.Publish(topic, msg); err != nil { //nolint:contextcheck
+		return fmt.Errorf("publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// itemSnapshot captures the event-facing state of an Item.
+func itemSnapshot(item *models.Item) domainevents.ItemSnapshot {
+	return domainevents.ItemSnapshot{
+		Name:      item.Name.String(),
+		CreatedAt: item.CreatedAt,
+	}
 }
 
 // rowToItem maps a db.ItemItem to a domain models.Item.
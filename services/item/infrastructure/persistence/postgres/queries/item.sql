@@ -7,13 +7,27 @@ SELECT id, org_id, name, created_at
 FROM item.items
 WHERE id = $1 AND org_id = $2;
 
+-- name: GetItemByIDForUpdate :one
+SELECT id, org_id, name, created_at
+FROM item.items
+WHERE id = $1 AND org_id = $2
+FOR UPDATE;
+
 -- name: FindItemsByOrgID :many
 SELECT id, org_id, name, created_at
 FROM item.items
 WHERE org_id = $1
-ORDER BY created_at DESC
+ORDER BY created_at DESC, id DESC
 LIMIT $2 OFFSET $3;
 
+-- name: FindItemsByOrgIDAfter :many
+SELECT id, org_id, name, created_at
+FROM item.items
+WHERE org_id = sqlc.arg('org_id')
+  AND (created_at, id) < (sqlc.arg('after_created_at')::timestamp, sqlc.arg('after_id')::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
 -- name: CountItemsByOrgID :one
 SELECT COUNT(*) FROM item.items
 WHERE org_id = $1;